@@ -2,14 +2,31 @@ package main
 
 import (
 	"api/src/config"
+	"api/src/controllers"
+	"api/src/policy"
+	"api/src/previews"
+	"api/src/ranking"
 	"api/src/router"
+	"api/src/scheduler"
+	"api/src/storage"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 )
 
 func main() {
 	config.Load()
+	policy.Load()
+	ranking.Load()
+	storage.Load()
+	previews.Start(controllers.SaveLinkPreview)
+	controllers.EnqueuePendingPreviews()
+	controllers.RescorePosts()
+	scheduler.Start(
+		scheduler.Job{Name: "publish scheduled posts", Interval: 30 * time.Second, Run: controllers.PublishScheduledPosts},
+		scheduler.Job{Name: "refresh follow suggestions", Interval: 10 * time.Minute, Run: controllers.RefreshSuggestions},
+	)
 	r := router.Generate()
 	fmt.Println("server go brr")
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Port), r))
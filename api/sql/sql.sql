@@ -1,6 +1,33 @@
 CREATE DATABASE IF NOT EXISTS devbook;
 USE devbook;
 
+DROP TABLE IF EXISTS ranking;
+DROP TABLE IF EXISTS scored_likes;
+DROP TABLE IF EXISTS post_likes;
+DROP TABLE IF EXISTS scored_reposts;
+DROP TABLE IF EXISTS list_members;
+DROP TABLE IF EXISTS lists;
+DROP TABLE IF EXISTS activities;
+DROP TABLE IF EXISTS author_daily_stats;
+DROP TABLE IF EXISTS post_daily_stats;
+DROP TABLE IF EXISTS post_impressions;
+DROP TABLE IF EXISTS follow_suggestions;
+DROP TABLE IF EXISTS pinned_posts;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS bookmark_collections;
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS post_links;
+DROP TABLE IF EXISTS link_previews;
+DROP TABLE IF EXISTS follow_requests;
+DROP TABLE IF EXISTS mutes;
+DROP TABLE IF EXISTS blocks;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS reposts;
+DROP TABLE IF EXISTS tag_followers;
+DROP TABLE IF EXISTS post_tags;
 DROP TABLE IF EXISTS posts;
 DROP TABLE IF EXISTS followers;
 DROP TABLE IF EXISTS users;
@@ -11,7 +38,23 @@ CREATE TABLE users(
   nick varchar(50) not null unique,
   email varchar(50) not null unique,
   password varchar(100) not null,
-  createdAt timestamp default current_timestamp()
+  notify_follow boolean not null default true,
+  notify_like boolean not null default true,
+  notify_comment boolean not null default true,
+  notify_mention boolean not null default true,
+  allow_dms boolean not null default false,
+  private boolean not null default false,
+  moderator boolean not null default false,
+  suspended boolean not null default false,
+  bio varchar(160) not null default '',
+  location varchar(50) not null default '',
+  website varchar(100) not null default '',
+  avatar_key varchar(255) not null default '',
+  banner_key varchar(255) not null default '',
+  suggestionsRefreshedAt timestamp null default null,
+  createdAt timestamp default current_timestamp(),
+
+  index (name)
 ) ENGINE=INNODB;
 
 CREATE TABLE followers(
@@ -37,6 +80,488 @@ CREATE TABLE posts(
   REFERENCES users(id)
   ON DELETE CASCADE,
 
+  visibility enum('public', 'followers', 'unlisted') not null default 'public',
+  status enum('published', 'draft', 'scheduled', 'held') not null default 'published',
+  publish_at timestamp null default null,
+  held_status enum('published', 'draft', 'scheduled') null default null,
+  hidden boolean not null default false,
+
+  quote_of_id int,
+  FOREIGN KEY (quote_of_id)
+  REFERENCES posts(id)
+  ON DELETE SET NULL,
+
+  parent_id int,
+  FOREIGN KEY (parent_id)
+  REFERENCES posts(id)
+  ON DELETE SET NULL,
+
   likes int default 0,
+  reposts int not null default 0,
+  quotes int not null default 0,
+  replies int not null default 0,
+  score double not null default 0,
+  createdAt timestamp default current_timestamp,
+
+  index (status, publish_at),
+  index (author_id, createdAt),
+  index (score)
+) ENGINE=INNODB;
+
+CREATE TABLE post_tags(
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  tag varchar(50) not null,
+  createdAt timestamp default current_timestamp,
+
+  primary key(post_id, tag),
+  index (tag, createdAt),
+  index (createdAt)
+) ENGINE=INNODB;
+
+CREATE TABLE tag_followers(
+  tag varchar(50) not null,
+
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  primary key(tag, user_id)
+) ENGINE=INNODB;
+
+CREATE TABLE notifications(
+  id int auto_increment primary key,
+
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  actor_id int,
+  FOREIGN KEY (actor_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  type varchar(20) not null,
+
+  post_id int,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  readAt timestamp null default null,
+  createdAt timestamp default current_timestamp,
+
+  index (user_id, readAt)
+) ENGINE=INNODB;
+
+CREATE TABLE conversations(
+  id int auto_increment primary key,
+
+  user_one_id int not null,
+  FOREIGN KEY (user_one_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  user_two_id int not null,
+  FOREIGN KEY (user_two_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+  updatedAt timestamp default current_timestamp,
+
+  unique (user_one_id, user_two_id)
+) ENGINE=INNODB;
+
+CREATE TABLE messages(
+  id int auto_increment primary key,
+
+  conversation_id int not null,
+  FOREIGN KEY (conversation_id)
+  REFERENCES conversations(id)
+  ON DELETE CASCADE,
+
+  sender_id int not null,
+  FOREIGN KEY (sender_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  content varchar(1000) not null,
+  readAt timestamp null default null,
+  createdAt timestamp default current_timestamp,
+
+  index (conversation_id, readAt)
+) ENGINE=INNODB;
+
+CREATE TABLE blocks(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  blocked_id int not null,
+  FOREIGN KEY (blocked_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, blocked_id)
+) ENGINE=INNODB;
+
+CREATE TABLE mutes(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  muted_id int not null,
+  FOREIGN KEY (muted_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, muted_id)
+) ENGINE=INNODB;
+
+CREATE TABLE follow_requests(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  requester_id int not null,
+  FOREIGN KEY (requester_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, requester_id)
+) ENGINE=INNODB;
+
+CREATE TABLE reposts(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, post_id),
+  index (post_id)
+) ENGINE=INNODB;
+
+CREATE TABLE attachments(
+  id int auto_increment primary key,
+
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  content_type varchar(50) not null,
+  size int not null,
+  width int not null default 0,
+  height int not null default 0,
+  storage_key varchar(255) not null,
+  thumbnail_key varchar(255),
+  createdAt timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE link_previews(
+  id int auto_increment primary key,
+  url varchar(2048) not null,
+  url_hash char(64) not null unique,
+  title varchar(300) not null default '',
+  description varchar(500) not null default '',
+  image varchar(2048) not null default '',
+  status enum('pending', 'ready', 'failed') not null default 'pending',
+  fetchedAt timestamp null default null,
+  createdAt timestamp default current_timestamp,
+
+  index (status)
+) ENGINE=INNODB;
+
+CREATE TABLE post_links(
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  preview_id int not null,
+  FOREIGN KEY (preview_id)
+  REFERENCES link_previews(id)
+  ON DELETE CASCADE,
+
+  position int not null,
+
+  primary key(post_id, preview_id)
+) ENGINE=INNODB;
+
+CREATE TABLE reports(
+  id int auto_increment primary key,
+
+  reporter_id int,
+  FOREIGN KEY (reporter_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  reason enum('spam', 'harassment', 'hate_speech', 'violence', 'nudity', 'misinformation', 'other', 'policy') not null,
+  details varchar(500) not null default '',
+  status enum('open', 'claimed', 'resolved') not null default 'open',
+
+  moderator_id int,
+  FOREIGN KEY (moderator_id)
+  REFERENCES users(id)
+  ON DELETE SET NULL,
+
+  action enum('dismiss', 'hide_post', 'suspend_author'),
+  claimedAt timestamp null default null,
+  resolvedAt timestamp null default null,
+  createdAt timestamp default current_timestamp,
+
+  index (status, createdAt)
+) ENGINE=INNODB;
+
+CREATE TABLE bookmark_collections(
+  id int auto_increment primary key,
+
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  name varchar(50) not null,
   createdAt timestamp default current_timestamp
 ) ENGINE=INNODB;
+
+CREATE TABLE bookmarks(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  collection_id int,
+  FOREIGN KEY (collection_id)
+  REFERENCES bookmark_collections(id)
+  ON DELETE SET NULL,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, post_id),
+  index (user_id, createdAt)
+) ENGINE=INNODB;
+
+CREATE TABLE pinned_posts(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  position int not null,
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, post_id)
+) ENGINE=INNODB;
+
+CREATE TABLE follow_suggestions(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  suggested_id int not null,
+  FOREIGN KEY (suggested_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  score double not null,
+  mutuals int not null,
+
+  primary key(user_id, suggested_id),
+  index (user_id, score)
+) ENGINE=INNODB;
+
+CREATE TABLE post_impressions(
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  viewer_id int not null,
+  FOREIGN KEY (viewer_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  day date not null,
+
+  primary key(post_id, viewer_id, day)
+) ENGINE=INNODB;
+
+CREATE TABLE post_daily_stats(
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  day date not null,
+  impressions int not null default 0,
+  likes int not null default 0,
+
+  primary key(post_id, day)
+) ENGINE=INNODB;
+
+CREATE TABLE author_daily_stats(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  day date not null,
+  impressions int not null default 0,
+  likes int not null default 0,
+  new_followers int not null default 0,
+
+  primary key(user_id, day)
+) ENGINE=INNODB;
+
+CREATE TABLE activities(
+  id bigint auto_increment primary key,
+
+  actor_id int not null,
+  FOREIGN KEY (actor_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  type enum('follow', 'like', 'comment', 'post') not null,
+
+  user_id int,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  index (actor_id, id)
+) ENGINE=INNODB;
+
+CREATE TABLE lists(
+  id int auto_increment primary key,
+
+  owner_id int not null,
+  FOREIGN KEY (owner_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  name varchar(50) not null,
+  description varchar(160) not null default '',
+  private boolean not null default false,
+  createdAt timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE list_members(
+  list_id int not null,
+  FOREIGN KEY (list_id)
+  REFERENCES lists(id)
+  ON DELETE CASCADE,
+
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(list_id, user_id),
+  index (user_id)
+) ENGINE=INNODB;
+
+CREATE TABLE scored_reposts(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, post_id)
+) ENGINE=INNODB;
+
+CREATE TABLE post_likes(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, post_id),
+  index (post_id)
+) ENGINE=INNODB;
+
+CREATE TABLE scored_likes(
+  user_id int not null,
+  FOREIGN KEY (user_id)
+  REFERENCES users(id)
+  ON DELETE CASCADE,
+
+  post_id int not null,
+  FOREIGN KEY (post_id)
+  REFERENCES posts(id)
+  ON DELETE CASCADE,
+
+  createdAt timestamp default current_timestamp,
+
+  primary key(user_id, post_id)
+) ENGINE=INNODB;
+
+CREATE TABLE ranking(
+  half_life_hours double not null
+) ENGINE=INNODB;
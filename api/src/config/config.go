@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +18,40 @@ var (
 	DatabaseConnectionString = ""
 	// SecretKey is used the sign the token
 	SecretKey []byte
+	// StorageDriver chooses where uploaded files are kept: local or s3
+	StorageDriver = ""
+	// StoragePath is the directory of the local storage
+	StoragePath = ""
+	// MediaURL is the public address of the files in the local storage
+	MediaURL = ""
+	// S3Endpoint is the address of the S3 compatible service
+	S3Endpoint = ""
+	// S3Region of the bucket
+	S3Region = ""
+	// S3Bucket where the files are kept
+	S3Bucket = ""
+	// S3AccessKey identifies the API in the S3 compatible service
+	S3AccessKey = ""
+	// S3SecretKey signs the requests to the S3 compatible service
+	S3SecretKey = ""
+	// S3PublicURL is the public address of the bucket, the endpoint and bucket are used when it is empty
+	S3PublicURL = ""
+	// MaxUploadSize is the biggest file, in bytes, that can be uploaded
+	MaxUploadSize int64 = 0
+	// BannedWords make a post be rejected
+	BannedWords []string
+	// ReviewWords make a post be held for review by the moderators
+	ReviewWords []string
+	// PolicyMaxLinks is how many links the content policy allows in a post, posts with more are rejected.
+	// It is unrelated to how many links get a preview
+	PolicyMaxLinks = 0
+	// MaxPostsPerHour is how many posts a user can create in an hour
+	MaxPostsPerHour = 0
+	// DuplicateWindow is how long an author can't post the same content again
+	DuplicateWindow time.Duration
+	// RankHalfLife is how long it takes the likes, replies and reposts of a post to weigh half as much in the explore feed.
+	// The scores are stored, so changing it rescores every post when the API starts
+	RankHalfLife time.Duration
 )
 
 //Load environment variables
@@ -31,4 +67,63 @@ func Load() {
 	DatabaseConnectionString = fmt.Sprintf("%s:%s@/%s?charset=utf8&parseTime=True&loc=Local", os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"))
 
 	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+
+	StorageDriver = os.Getenv("STORAGE_DRIVER")
+	if StorageDriver == "" {
+		StorageDriver = "local"
+	}
+	StoragePath = os.Getenv("STORAGE_PATH")
+	if StoragePath == "" {
+		StoragePath = "uploads"
+	}
+	MediaURL = os.Getenv("MEDIA_URL")
+	if MediaURL == "" {
+		MediaURL = fmt.Sprintf("http://localhost:%d/media", Port)
+	}
+	S3Endpoint = os.Getenv("S3_ENDPOINT")
+	S3Region = os.Getenv("S3_REGION")
+	if S3Region == "" {
+		S3Region = "us-east-1"
+	}
+	S3Bucket = os.Getenv("S3_BUCKET")
+	S3AccessKey = os.Getenv("S3_ACCESS_KEY")
+	S3SecretKey = os.Getenv("S3_SECRET_KEY")
+	S3PublicURL = os.Getenv("S3_PUBLIC_URL")
+
+	MaxUploadSize, error = strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE"), 10, 64)
+	if error != nil || MaxUploadSize <= 0 {
+		MaxUploadSize = 5 << 20
+	}
+
+	BannedWords = list(os.Getenv("BANNED_WORDS"))
+	ReviewWords = list(os.Getenv("REVIEW_WORDS"))
+	PolicyMaxLinks, error = strconv.Atoi(os.Getenv("POLICY_MAX_LINKS"))
+	if error != nil || PolicyMaxLinks < 0 {
+		PolicyMaxLinks = 3
+	}
+	MaxPostsPerHour, error = strconv.Atoi(os.Getenv("MAX_POSTS_PER_HOUR"))
+	if error != nil || MaxPostsPerHour <= 0 {
+		MaxPostsPerHour = 30
+	}
+	duplicateWindowHours, error := strconv.Atoi(os.Getenv("DUPLICATE_WINDOW_HOURS"))
+	if error != nil || duplicateWindowHours < 0 {
+		duplicateWindowHours = 24
+	}
+	DuplicateWindow = time.Duration(duplicateWindowHours) * time.Hour
+	rankHalfLifeHours, error := strconv.ParseFloat(os.Getenv("RANK_HALF_LIFE_HOURS"), 64)
+	if error != nil || rankHalfLifeHours <= 0 {
+		rankHalfLifeHours = 12
+	}
+	RankHalfLife = time.Duration(rankHalfLifeHours * float64(time.Hour))
+}
+
+//list splits a comma separated variable, leaving out the empty items
+func list(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//FetchUserActivity fetches what the user did recently: their follows, likes, comments and posts, as far as the viewer is allowed to see
+func FetchUserActivity(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	userID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	exists, error := repositories.NewUserRespository(db).Exists(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(userID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !exists || blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+
+	activities, error := repositories.NewActivityRepository(db).FetchByActor(userID, viewerID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, activities)
+}
+
+//recordActivity appends to the activity log, failures are only logged so they don't undo the action
+func recordActivity(db *sql.DB, activity models.Activity) {
+	if error := repositories.NewActivityRepository(db).Record(activity); error != nil {
+		log.Printf("could not record the %s activity of user %d: %v", activity.Type, activity.ActorID, error)
+	}
+}
@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/config"
+	"api/src/markdown"
+	"api/src/media"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"api/src/storage"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const thumbnailSize = 400
+
+//UploadAttachment uploads a file to a post of the user, the file must be sent in the file field of a multipart form
+func UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	postRepository := repositories.NewPostRepository(db)
+	post, error := postRepository.FetchByID(postID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.AuthorID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("You can't add files to a post that is not yours"))
+		return
+	}
+
+	repository := repositories.NewAttachmentRepository(db)
+	count, error := repository.Count(postID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if count >= models.MaxAttachmentsPerPost {
+		responses.Error(w, http.StatusBadRequest, fmt.Errorf("A post can't have more than %d files", models.MaxAttachmentsPerPost))
+		return
+	}
+
+	content, status, error := readUpload(w, r)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+
+	attachment, error := storeAttachment(postID, content)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+	attachment.ID, error = repository.Create(attachment, models.MaxAttachmentsPerPost)
+	if error != nil {
+		deleteFiles(attachment.Key, attachment.ThumbnailKey)
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if attachment.ID == 0 {
+		deleteFiles(attachment.Key, attachment.ThumbnailKey)
+		responses.Error(w, http.StatusBadRequest, fmt.Errorf("A post can't have more than %d files", models.MaxAttachmentsPerPost))
+		return
+	}
+	attachment.CreatedAt = time.Now()
+	setAttachmentURLs(&attachment)
+
+	responses.JSON(w, http.StatusCreated, attachment)
+}
+
+//DeleteAttachment removes a file from a post of the user
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	attachmentID, error := strconv.ParseUint(parameters["attachmentID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	postRepository := repositories.NewPostRepository(db)
+	post, error := postRepository.FetchByID(postID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.AuthorID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("You can't remove files from a post that is not yours"))
+		return
+	}
+
+	repository := repositories.NewAttachmentRepository(db)
+	attachment, error := repository.FetchByID(postID, attachmentID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if attachment.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("File not found"))
+		return
+	}
+	if error = repository.Delete(attachmentID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	deleteFiles(attachment.Key, attachment.ThumbnailKey)
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//ServeMedia serves a file from the storage
+func ServeMedia(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	file, error := storage.Default.Open(key)
+	if error == storage.ErrNotFound {
+		responses.Error(w, http.StatusNotFound, error)
+		return
+	}
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	read, error := io.ReadFull(file, header)
+	if error != nil && error != io.ErrUnexpectedEOF && error != io.EOF {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	w.Header().Set("Content-Type", http.DetectContentType(header[:read]))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.WriteHeader(http.StatusOK)
+	w.Write(header[:read])
+	io.Copy(w, file)
+}
+
+//readUpload reads the file of a multipart form, refusing files bigger than the configured size.
+//The returned status code tells the client what went wrong
+func readUpload(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxUploadSize+1<<20)
+	file, _, error := r.FormFile("file")
+	if error != nil {
+		return nil, http.StatusBadRequest, errors.New("The file must be sent in the file field of a multipart form, within the size limit")
+	}
+	defer file.Close()
+
+	content, error := ioutil.ReadAll(io.LimitReader(file, config.MaxUploadSize+1))
+	if error != nil {
+		return nil, http.StatusUnprocessableEntity, error
+	}
+	if int64(len(content)) > config.MaxUploadSize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("The file can't be bigger than %d bytes", config.MaxUploadSize)
+	}
+	if len(content) == 0 {
+		return nil, http.StatusBadRequest, errors.New("The file is empty")
+	}
+	return content, http.StatusOK, nil
+}
+
+//storeAttachment checks the real type of the file, saves it and a thumbnail when it is an image
+func storeAttachment(postID uint64, content []byte) (models.Attachment, error) {
+	contentType, _, error := mime.ParseMediaType(http.DetectContentType(content))
+	if error != nil {
+		return models.Attachment{}, error
+	}
+	extension, allowed := models.AllowedAttachmentTypes[contentType]
+	if !allowed {
+		return models.Attachment{}, fmt.Errorf("Files of type %s are not allowed", contentType)
+	}
+
+	name, error := randomName()
+	if error != nil {
+		return models.Attachment{}, error
+	}
+	attachment := models.Attachment{
+		PostID:      postID,
+		ContentType: contentType,
+		Size:        uint64(len(content)),
+		Key:         fmt.Sprintf("attachments/%d/%s%s", postID, name, extension),
+	}
+
+	var thumbnail []byte
+	if media.Decodable[contentType] {
+		decodedImage, error := media.Decode(content)
+		if error != nil {
+			return models.Attachment{}, fmt.Errorf("The image could not be read: %v", error)
+		}
+		attachment.Width = uint64(decodedImage.Bounds().Dx())
+		attachment.Height = uint64(decodedImage.Bounds().Dy())
+		thumbnail, error = media.EncodeJPEG(media.Fit(decodedImage, thumbnailSize, thumbnailSize))
+		if error != nil {
+			return models.Attachment{}, error
+		}
+		attachment.ThumbnailKey = fmt.Sprintf("attachments/%d/%s-thumbnail.jpg", postID, name)
+	}
+
+	if error = storage.Default.Save(attachment.Key, content, contentType); error != nil {
+		return models.Attachment{}, error
+	}
+	if thumbnail != nil {
+		if error = storage.Default.Save(attachment.ThumbnailKey, thumbnail, "image/jpeg"); error != nil {
+			deleteFiles(attachment.Key)
+			return models.Attachment{}, error
+		}
+	}
+	return attachment, nil
+}
+
+//completePosts fills in the posts what is not kept in the posts table, like their files, link previews and rendered content,
+//and whether the viewer bookmarked them
+func completePosts(db *sql.DB, viewerID uint64, posts ...*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	postIDs := make([]uint64, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	attachments, error := repositories.NewAttachmentRepository(db).FetchByPosts(postIDs)
+	if error != nil {
+		return error
+	}
+	links, error := repositories.NewLinkPreviewRepository(db).FetchByPosts(postIDs)
+	if error != nil {
+		return error
+	}
+	bookmarked, error := repositories.NewBookmarkRepository(db).BookmarkedPosts(viewerID, postIDs)
+	if error != nil {
+		return error
+	}
+	for _, post := range posts {
+		post.ContentHTML = markdown.Render(post.Content)
+		post.Links = links[post.ID]
+		post.BookmarkedByMe = bookmarked[post.ID]
+		post.Attachments = attachments[post.ID]
+		for i := range post.Attachments {
+			setAttachmentURLs(&post.Attachments[i])
+		}
+	}
+	return nil
+}
+
+//postReferences returns references to the posts and to all their nested replies
+func postReferences(posts []models.Post) []*models.Post {
+	var references []*models.Post
+	for i := range posts {
+		references = append(references, &posts[i])
+		references = append(references, postReferences(posts[i].Children)...)
+	}
+	return references
+}
+
+func setAttachmentURLs(attachment *models.Attachment) {
+	attachment.URL = storage.Default.URL(attachment.Key)
+	if attachment.ThumbnailKey != "" {
+		attachment.ThumbnailURL = storage.Default.URL(attachment.ThumbnailKey)
+	}
+}
+
+//deleteFiles removes files from the storage. Failures are only logged because the files are no longer referenced
+func deleteFiles(keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if error := storage.Default.Delete(key); error != nil {
+			log.Printf("could not delete file %s: %v", key, error)
+		}
+	}
+}
+
+func randomName() (string, error) {
+	bytes := make([]byte, 16)
+	if _, error := rand.Read(bytes); error != nil {
+		return "", error
+	}
+	return hex.EncodeToString(bytes), nil
+}
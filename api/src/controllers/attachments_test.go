@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"api/src/storage"
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func useLocalStorage(t *testing.T) {
+	t.Helper()
+	previous := storage.Default
+	storage.Default = storage.NewLocal(t.TempDir(), "http://localhost/media")
+	t.Cleanup(func() { storage.Default = previous })
+}
+
+func readStored(t *testing.T, key string) []byte {
+	t.Helper()
+	file, error := storage.Default.Open(key)
+	if error != nil {
+		t.Fatalf("opening %s: %v", key, error)
+	}
+	defer file.Close()
+	content, error := ioutil.ReadAll(file)
+	if error != nil {
+		t.Fatalf("reading %s: %v", key, error)
+	}
+	return content
+}
+
+func TestStoreAttachmentImage(t *testing.T) {
+	useLocalStorage(t)
+	var buffer bytes.Buffer
+	if error := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 1000, 500))); error != nil {
+		t.Fatalf("encoding the png: %v", error)
+	}
+
+	attachment, error := storeAttachment(7, buffer.Bytes())
+	if error != nil {
+		t.Fatalf("storeAttachment: %v", error)
+	}
+	if attachment.ContentType != "image/png" || attachment.Width != 1000 || attachment.Height != 500 {
+		t.Errorf("attachment is %s %dx%d, want image/png 1000x500", attachment.ContentType, attachment.Width, attachment.Height)
+	}
+	if !strings.HasPrefix(attachment.Key, "attachments/7/") || !strings.HasSuffix(attachment.Key, ".png") {
+		t.Errorf("key %s is not a png of the post", attachment.Key)
+	}
+	if !bytes.Equal(readStored(t, attachment.Key), buffer.Bytes()) {
+		t.Errorf("the stored file differs from the upload")
+	}
+
+	thumbnail, error := jpeg.Decode(bytes.NewReader(readStored(t, attachment.ThumbnailKey)))
+	if error != nil {
+		t.Fatalf("the thumbnail is not a jpeg: %v", error)
+	}
+	if size := thumbnail.Bounds().Size(); size != image.Pt(thumbnailSize, thumbnailSize/2) {
+		t.Errorf("thumbnail size is %v, want %dx%d", size, thumbnailSize, thumbnailSize/2)
+	}
+}
+
+func TestStoreAttachmentSniffsTheType(t *testing.T) {
+	useLocalStorage(t)
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+	}{
+		{"text", []byte("just some notes"), "text/plain"},
+		{"pdf", []byte("%PDF-1.4\n%âãÏÓ\n"), "application/pdf"},
+		{"html", []byte("<!DOCTYPE html><script>alert(1)</script>"), ""},
+		{"executable", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff"), ""},
+		{"broken png", []byte("\x89PNG\r\n\x1a\nnot really a png"), ""},
+	}
+	for _, test := range tests {
+		attachment, error := storeAttachment(1, test.content)
+		if test.contentType == "" {
+			if error == nil {
+				t.Errorf("%s: stored as %s, want an error", test.name, attachment.ContentType)
+			}
+			continue
+		}
+		if error != nil {
+			t.Errorf("%s: %v", test.name, error)
+			continue
+		}
+		if attachment.ContentType != test.contentType || attachment.ThumbnailKey != "" {
+			t.Errorf("%s: stored as %s with thumbnail %q, want %s without thumbnail",
+				test.name, attachment.ContentType, attachment.ThumbnailKey, test.contentType)
+		}
+	}
+}
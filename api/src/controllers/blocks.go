@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/repositories"
+	"api/src/responses"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//BlockUser blocks a user, removing the follows between them in both directions
+func BlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	blockedID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userID == blockedID {
+		responses.Error(w, http.StatusForbidden, errors.New("Impossible to block yourself"))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewBlockRepository(db)
+	if error = repository.Block(userID, blockedID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//UnblockUser removes the block of a user
+func UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	blockedID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewBlockRepository(db)
+	if error = repository.Unblock(userID, blockedID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//FetchBlockedUsers fetches the users blocked by the user
+func FetchBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewBlockRepository(db)
+	users, error := repository.FetchBlocked(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	setUsersImageURLs(users)
+	responses.JSON(w, http.StatusOK, users)
+}
+
+//MuteUser hides the posts of a user from the timeline
+func MuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	mutedID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userID == mutedID {
+		responses.Error(w, http.StatusForbidden, errors.New("Impossible to mute yourself"))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewMuteRepository(db)
+	if error = repository.Mute(userID, mutedID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//UnmuteUser shows the posts of a muted user in the timeline again
+func UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	mutedID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewMuteRepository(db)
+	if error = repository.Unmute(userID, mutedID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//FetchMutedUsers fetches the users muted by the user
+func FetchMutedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewMuteRepository(db)
+	users, error := repository.FetchMuted(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	setUsersImageURLs(users)
+	responses.JSON(w, http.StatusOK, users)
+}
@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//BookmarkPost saves a post the user can see in their bookmarks. The body can name the collection to keep it in
+func BookmarkPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+	var bookmark models.Bookmark
+	if len(requestBody) > 0 {
+		if error = json.Unmarshal(requestBody, &bookmark); error != nil {
+			responses.Error(w, http.StatusBadRequest, error)
+			return
+		}
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	post, error := repositories.NewPostRepository(db).FetchVisible(postID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+
+	repository := repositories.NewBookmarkRepository(db)
+	if bookmark.CollectionID != 0 {
+		if _, status, error := fetchOwnCollection(repository, bookmark.CollectionID, userID); error != nil {
+			responses.Error(w, status, error)
+			return
+		}
+	}
+	if error = repository.Add(userID, postID, bookmark.CollectionID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//UnbookmarkPost removes a post from the bookmarks of the user
+func UnbookmarkPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	if error = repositories.NewBookmarkRepository(db).Remove(userID, postID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//FetchBookmarks fetches the bookmarked posts of the user, only those of a collection when it is given
+func FetchBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	var collectionID uint64
+	if value := r.URL.Query().Get("collection"); value != "" {
+		if collectionID, error = strconv.ParseUint(value, 10, 64); error != nil {
+			responses.Error(w, http.StatusBadRequest, error)
+			return
+		}
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewBookmarkRepository(db)
+	if collectionID != 0 {
+		if _, status, error := fetchOwnCollection(repository, collectionID, userID); error != nil {
+			responses.Error(w, status, error)
+			return
+		}
+	}
+	posts, error := repository.Fetch(userID, collectionID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if error = completePosts(db, userID, postReferences(posts)...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, posts)
+}
+
+//CreateCollection creates a bookmark collection for the user
+func CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	collection, error := readCollection(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	collection.UserID = userID
+	collection.ID, error = repositories.NewBookmarkRepository(db).CreateCollection(collection)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusCreated, collection)
+}
+
+//FetchCollections fetches the bookmark collections of the user
+func FetchCollections(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	collections, error := repositories.NewBookmarkRepository(db).FetchCollections(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, collections)
+}
+
+//UpdateCollection renames a bookmark collection of the user
+func UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	collectionID, error := strconv.ParseUint(parameters["collectionID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	collection, error := readCollection(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewBookmarkRepository(db)
+	if _, status, error := fetchOwnCollection(repository, collectionID, userID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = repository.RenameCollection(collectionID, collection.Name); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//DeleteCollection deletes a bookmark collection of the user, its posts stay bookmarked
+func DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	collectionID, error := strconv.ParseUint(parameters["collectionID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewBookmarkRepository(db)
+	if _, status, error := fetchOwnCollection(repository, collectionID, userID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = repository.DeleteCollection(collectionID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func readCollection(r *http.Request) (models.Collection, error) {
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		return models.Collection{}, error
+	}
+	var collection models.Collection
+	if error = json.Unmarshal(requestBody, &collection); error != nil {
+		return models.Collection{}, error
+	}
+	if error = collection.Prepare(); error != nil {
+		return models.Collection{}, error
+	}
+	return collection, nil
+}
+
+//fetchOwnCollection fetches a bookmark collection of the user, the returned status code tells the client what went wrong.
+//Collections of other users are reported as not found because they are private
+func fetchOwnCollection(repository *repositories.Bookmarks, collectionID, userID uint64) (models.Collection, int, error) {
+	collection, error := repository.FetchCollection(collectionID)
+	if error != nil {
+		return models.Collection{}, http.StatusInternalServerError, error
+	}
+	if collection.ID == 0 || collection.UserID != userID {
+		return models.Collection{}, http.StatusNotFound, errors.New("Collection not found")
+	}
+	return collection, http.StatusOK, nil
+}
@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/events"
+	"api/src/markdown"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// duePostsBatch is how many scheduled posts are published at a time
+const duePostsBatch = 100
+
+//FetchDrafts fetches the drafts of the user
+func FetchDrafts(w http.ResponseWriter, r *http.Request) {
+	fetchPostsByStatus(w, r, models.StatusDraft)
+}
+
+//FetchScheduledPosts fetches the scheduled posts of the user, the next to be published first
+func FetchScheduledPosts(w http.ResponseWriter, r *http.Request) {
+	fetchPostsByStatus(w, r, models.StatusScheduled)
+}
+
+func fetchPostsByStatus(w http.ResponseWriter, r *http.Request, status string) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewPostRepository(db)
+	posts, error := repository.FetchByStatus(userID, status, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if error = completePosts(db, userID, postReferences(posts)...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, posts)
+}
+
+//SchedulePost sets the publication time of a draft or scheduled post of the user
+func SchedulePost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+	var schedule models.Schedule
+	if error = json.Unmarshal(requestBody, &schedule); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if error = models.ValidatePublishAt(schedule.PublishAt); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewPostRepository(db)
+	post, status, error := fetchUnpublishedPost(repository, postID, userID)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = repository.Schedule(post.ID, schedule.PublishAt); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//UnschedulePost cancels the publication of a scheduled post, keeping it as a draft
+func UnschedulePost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewPostRepository(db)
+	post, status, error := fetchUnpublishedPost(repository, postID, userID)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = repository.Unschedule(post.ID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//PublishPost publishes a draft or scheduled post of the user right away
+func PublishPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewPostRepository(db)
+	post, status, error := fetchUnpublishedPost(repository, postID, userID)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = publishPost(db, post.ID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//PublishScheduledPosts publishes the scheduled posts whose time has come, it is run by the scheduler.
+//Posts missed while the API was stopped are published on the next run. A post that fails is
+//skipped for the rest of the run so it doesn't hold back the others, and tried again on the next one
+func PublishScheduledPosts() {
+	db, error := base.Connect()
+	if error != nil {
+		log.Printf("could not publish the scheduled posts: %v", error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewPostRepository(db)
+	var failedIDs []uint64
+	for {
+		postIDs, error := repository.FetchDueIDs(duePostsBatch, failedIDs)
+		if error != nil {
+			log.Printf("could not publish the scheduled posts: %v", error)
+			return
+		}
+		for _, postID := range postIDs {
+			if error = publishPost(db, postID); error != nil {
+				log.Printf("could not publish post %d: %v", postID, error)
+				failedIDs = append(failedIDs, postID)
+			}
+		}
+		if len(postIDs) < duePostsBatch {
+			return
+		}
+	}
+}
+
+//fetchUnpublishedPost fetches a draft or scheduled post of the user, held posts are left to the moderators, the returned status code tells the client what went wrong
+func fetchUnpublishedPost(repository *repositories.Posts, postID, userID uint64) (models.Post, int, error) {
+	post, error := repository.FetchByID(postID)
+	if error != nil {
+		return models.Post{}, http.StatusInternalServerError, error
+	}
+	if post.ID == 0 {
+		return models.Post{}, http.StatusNotFound, errors.New("Post not found")
+	}
+	if post.AuthorID != userID {
+		return models.Post{}, http.StatusForbidden, errors.New("You can't change a post that is not yours")
+	}
+	if post.Status == models.StatusPublished {
+		return models.Post{}, http.StatusBadRequest, errors.New("The post is already published")
+	}
+	if post.Status == models.StatusHeld {
+		return models.Post{}, http.StatusBadRequest, errors.New("The post is waiting for a moderator to review it")
+	}
+	return post, http.StatusOK, nil
+}
+
+//publishPost publishes the post and announces it, unless it was published in the meantime
+func publishPost(db *sql.DB, postID uint64) error {
+	repository := repositories.NewPostRepository(db)
+	published, error := repository.Publish(postID)
+	if error != nil || !published {
+		return error
+	}
+	return announcePublishedPost(repository, db, postID)
+}
+
+//announcePublishedPost fetches a post that was just published to announce it
+func announcePublishedPost(repository *repositories.Posts, db *sql.DB, postID uint64) error {
+	post, error := repository.FetchByID(postID)
+	if error != nil {
+		return error
+	}
+	post.ContentHTML = markdown.Render(post.Content)
+	announcePost(db, post)
+	return nil
+}
+
+//announcePost records the publication of a post in the activity of its author, notifies the users mentioned
+//in it and the author of the post it replies to, then pushes it to the streams of the followers of its author
+func announcePost(db *sql.DB, post models.Post) {
+	notifyMentions(db, post, models.ParseMentions(post.Content))
+	if post.ParentID == 0 {
+		recordActivity(db, models.Activity{ActorID: post.AuthorID, Type: models.ActivityPost, PostID: post.ID})
+	} else {
+		parent, error := repositories.NewPostRepository(db).FetchByID(post.ParentID)
+		if error != nil {
+			log.Printf("could not notify the reply to post %d: %v", post.ParentID, error)
+		}
+		if parent.ID != 0 {
+			recordActivity(db, models.Activity{ActorID: post.AuthorID, Type: models.ActivityComment, UserID: parent.AuthorID, PostID: post.ID})
+			notify(db, models.Notification{
+				UserID:  parent.AuthorID,
+				ActorID: post.AuthorID,
+				Type:    models.NotificationComment,
+				PostID:  post.ID,
+			})
+		}
+	}
+	publishToFollowers(db, post.AuthorID, events.TypePost, post, false)
+}
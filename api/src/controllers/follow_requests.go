@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//FetchFollowRequests fetches the users waiting for the approval of the user to follow them
+func FetchFollowRequests(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	users, error := repository.FetchFollowRequests(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	setUsersImageURLs(users)
+	responses.JSON(w, http.StatusOK, users)
+}
+
+//ApproveFollowRequest lets the requester follow the user
+func ApproveFollowRequest(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	requesterID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	approved, error := approveFollowRequest(db, userID, requesterID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !approved {
+		responses.Error(w, http.StatusNotFound, errors.New("Follow request not found"))
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//approveFollowRequest turns the request into a follow, records it and lets the requester know.
+//It returns false when there was no request
+func approveFollowRequest(db *sql.DB, userID, requesterID uint64) (bool, error) {
+	approved, error := repositories.NewUserRespository(db).ApproveFollowRequest(userID, requesterID)
+	if error != nil || !approved {
+		return false, error
+	}
+	recordActivity(db, models.Activity{ActorID: requesterID, Type: models.ActivityFollow, UserID: userID})
+	notify(db, models.Notification{
+		UserID:  requesterID,
+		ActorID: userID,
+		Type:    models.NotificationFollowAccepted,
+	})
+	return true, nil
+}
+
+//approveFollowRequests approves the pending requests of a user whose account became public. Failures are only
+//logged, the requests left can still be approved one by one
+func approveFollowRequests(db *sql.DB, userID uint64) {
+	requesters, error := repositories.NewUserRespository(db).FetchFollowRequests(userID)
+	if error != nil {
+		log.Printf("could not fetch the follow requests of user %d: %v", userID, error)
+		return
+	}
+	for _, requester := range requesters {
+		if _, error = approveFollowRequest(db, userID, requester.ID); error != nil {
+			log.Printf("could not approve the follow request of user %d to user %d: %v", requester.ID, userID, error)
+		}
+	}
+}
+
+//RejectFollowRequest refuses the request of the requester to follow the user
+func RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	requesterID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	if error = repository.RejectFollowRequest(userID, requesterID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
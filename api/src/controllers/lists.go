@@ -0,0 +1,415 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//CreateList creates a list owned by the user
+func CreateList(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	list, error := readList(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	list.OwnerID = userID
+	list.ID, error = repositories.NewListRepository(db).Create(list)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusCreated, list)
+}
+
+//FetchUserLists fetches the lists of a user, their private lists only when the viewer is the user
+func FetchUserLists(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	userID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(userID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+
+	lists, error := repositories.NewListRepository(db).FetchByOwner(userID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, lists)
+}
+
+//FetchList fetches a list the viewer is allowed to see
+func FetchList(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	listID, error := strconv.ParseUint(parameters["listID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	list, status, error := fetchVisibleList(db, listID, viewerID)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, list)
+}
+
+//UpdateList changes the name, description and privacy of a list of the user
+func UpdateList(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	listID, error := strconv.ParseUint(parameters["listID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	list, error := readList(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewListRepository(db)
+	if _, status, error := fetchOwnList(repository, listID, userID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = repository.Update(listID, list); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//DeleteList deletes a list of the user
+func DeleteList(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	listID, error := strconv.ParseUint(parameters["listID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewListRepository(db)
+	if _, status, error := fetchOwnList(repository, listID, userID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = repository.Delete(listID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//AddListMember adds a user to a list of the user, members don't need to be followed
+func AddListMember(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	listID, error := strconv.ParseUint(parameters["listID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	memberID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewListRepository(db)
+	if _, status, error := fetchOwnList(repository, listID, userID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+
+	exists, error := repositories.NewUserRespository(db).Exists(memberID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(memberID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !exists || blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+
+	added, error := repository.AddMember(listID, memberID, models.MaxListMembers)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !added {
+		responses.Error(w, http.StatusBadRequest, fmt.Errorf("A list can't have more than %d members", models.MaxListMembers))
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//RemoveListMember takes a user out of a list of the user
+func RemoveListMember(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	listID, error := strconv.ParseUint(parameters["listID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	memberID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewListRepository(db)
+	if _, status, error := fetchOwnList(repository, listID, userID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if error = repository.RemoveMember(listID, memberID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//FetchListMembers fetches the members of a list the viewer is allowed to see
+func FetchListMembers(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	listID, error := strconv.ParseUint(parameters["listID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	if _, status, error := fetchVisibleList(db, listID, viewerID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	members, error := repositories.NewListRepository(db).FetchMembers(listID, viewerID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	setUsersImageURLs(members)
+	responses.JSON(w, http.StatusOK, members)
+}
+
+//FetchListPosts fetches the timeline of a list the viewer is allowed to see, with the posts of its members the viewer can see
+func FetchListPosts(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	listID, error := strconv.ParseUint(parameters["listID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	if _, status, error := fetchVisibleList(db, listID, viewerID); error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	posts, error := repositories.NewListRepository(db).FetchPosts(listID, viewerID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if error = completePosts(db, viewerID, postReferences(posts)...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	recordImpressions(db, viewerID, posts)
+	responses.JSON(w, http.StatusOK, posts)
+}
+
+func readList(r *http.Request) (models.List, error) {
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		return models.List{}, error
+	}
+	var list models.List
+	if error = json.Unmarshal(requestBody, &list); error != nil {
+		return models.List{}, error
+	}
+	if error = list.Prepare(); error != nil {
+		return models.List{}, error
+	}
+	return list, nil
+}
+
+//fetchVisibleList fetches a list the viewer is allowed to see, the returned status code tells the client what went wrong.
+//Private lists of other users and lists of users blocked by or blocking the viewer are reported as not found
+func fetchVisibleList(db *sql.DB, listID, viewerID uint64) (models.List, int, error) {
+	list, error := repositories.NewListRepository(db).FetchByID(listID)
+	if error != nil {
+		return models.List{}, http.StatusInternalServerError, error
+	}
+	if list.ID == 0 || (list.Private && list.OwnerID != viewerID) {
+		return models.List{}, http.StatusNotFound, errors.New("List not found")
+	}
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(list.OwnerID, viewerID)
+	if error != nil {
+		return models.List{}, http.StatusInternalServerError, error
+	}
+	if blocked {
+		return models.List{}, http.StatusNotFound, errors.New("List not found")
+	}
+	return list, http.StatusOK, nil
+}
+
+//fetchOwnList fetches a list of the user, the returned status code tells the client what went wrong
+func fetchOwnList(repository *repositories.Lists, listID, userID uint64) (models.List, int, error) {
+	list, error := repository.FetchByID(listID)
+	if error != nil {
+		return models.List{}, http.StatusInternalServerError, error
+	}
+	if list.ID == 0 || (list.Private && list.OwnerID != userID) {
+		return models.List{}, http.StatusNotFound, errors.New("List not found")
+	}
+	if list.OwnerID != userID {
+		return models.List{}, http.StatusForbidden, errors.New("You can't change a list that is not yours")
+	}
+	return list, http.StatusOK, nil
+}
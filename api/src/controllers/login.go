@@ -8,6 +8,7 @@ import (
 	"api/src/responses"
 	"api/src/security"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 )
@@ -44,6 +45,15 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		responses.Error(w, http.StatusUnauthorized, error)
 		return
 	}
+	suspended, error := repository.IsSuspended(userSavedInDatabase.ID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if suspended {
+		responses.Error(w, http.StatusForbidden, errors.New("This account is suspended"))
+		return
+	}
 	token, error := authentication.CreateToken(userSavedInDatabase.ID)
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/events"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//FetchConversations fetches the conversations of the user
+func FetchConversations(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewMessageRepository(db)
+	conversations, error := repository.FetchConversations(userID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, conversations)
+}
+
+//FetchMessages fetches the messages of a conversation of the user
+func FetchMessages(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	conversationID, error := strconv.ParseUint(parameters["conversationID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewMessageRepository(db)
+	otherUserID, error := repository.FetchOtherParticipant(conversationID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if otherUserID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Conversation not found"))
+		return
+	}
+
+	messages, error := repository.FetchMessages(conversationID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, messages)
+}
+
+//SendMessageToUser sends a message to a user, starting the conversation when needed
+func SendMessageToUser(w http.ResponseWriter, r *http.Request) {
+	senderID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	recipientID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if senderID == recipientID {
+		responses.Error(w, http.StatusForbidden, errors.New("Impossible to message yourself"))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	sendMessage(w, r, db, senderID, recipientID, 0)
+}
+
+//SendMessage sends a message in a conversation of the user
+func SendMessage(w http.ResponseWriter, r *http.Request) {
+	senderID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	conversationID, error := strconv.ParseUint(parameters["conversationID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewMessageRepository(db)
+	recipientID, error := repository.FetchOtherParticipant(conversationID, senderID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if recipientID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Conversation not found"))
+		return
+	}
+
+	sendMessage(w, r, db, senderID, recipientID, conversationID)
+}
+
+//MarkConversationRead marks the messages the user received in a conversation as read
+func MarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	conversationID, error := strconv.ParseUint(parameters["conversationID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewMessageRepository(db)
+	otherUserID, error := repository.FetchOtherParticipant(conversationID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if otherUserID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Conversation not found"))
+		return
+	}
+	if error = repository.MarkRead(conversationID, userID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//sendMessage reads the message from the request and delivers it to the recipient when they accept it.
+//A zero conversationID means the conversation between the users must be found or created
+func sendMessage(w http.ResponseWriter, r *http.Request, db *sql.DB, senderID, recipientID, conversationID uint64) {
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+	var message models.Message
+	if error = json.Unmarshal(requestBody, &message); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if error = message.Prepare(); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	repository := repositories.NewMessageRepository(db)
+	allowed, error := repository.CanMessage(senderID, recipientID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !allowed {
+		responses.Error(w, http.StatusForbidden, errors.New("This user only accepts messages from mutual followers"))
+		return
+	}
+
+	if conversationID == 0 {
+		conversationID, error = repository.FetchOrCreateConversation(senderID, recipientID)
+		if error != nil {
+			responses.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+	}
+
+	message.ConversationID = conversationID
+	message.SenderID = senderID
+	message.ID, error = repository.Send(message)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	message.CreatedAt = time.Now()
+
+	publish(events.TypeMessage, []uint64{recipientID}, message)
+	responses.JSON(w, http.StatusCreated, message)
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/events"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//FetchNotifications fetches the notifications of the user and how many are unread
+func FetchNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewNotificationRepository(db)
+	notifications, error := repository.Fetch(userID, unreadOnly, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	unread, error := repository.CountUnread(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, struct {
+		Unread        uint64                `json:"unread"`
+		Notifications []models.Notification `json:"notifications"`
+	}{
+		Unread:        unread,
+		Notifications: notifications,
+	})
+}
+
+//MarkNotificationRead marks a notification of the user as read
+func MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	notificationID, error := strconv.ParseUint(parameters["notificationID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewNotificationRepository(db)
+	if error = repository.MarkRead(userID, notificationID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//MarkAllNotificationsRead marks all the notifications of the user as read
+func MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewNotificationRepository(db)
+	if error = repository.MarkAllRead(userID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//FetchNotificationPreferences fetches which types of notification the user receives
+func FetchNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewNotificationRepository(db)
+	preferences, error := repository.FetchPreferences(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, preferences)
+}
+
+//UpdateNotificationPreferences changes which types of notification the user receives
+func UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+	var preferences models.NotificationPreferences
+	if error = json.Unmarshal(requestBody, &preferences); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewNotificationRepository(db)
+	if error = repository.UpdatePreferences(userID, preferences); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//notify creates a notification. Failures are only logged because they must not undo the action that caused them
+func notify(db *sql.DB, notification models.Notification) {
+	repository := repositories.NewNotificationRepository(db)
+	notificationID, error := repository.Create(notification)
+	if error != nil {
+		log.Printf("could not create %s notification for user %d: %v", notification.Type, notification.UserID, error)
+		return
+	}
+	if notificationID == 0 {
+		return
+	}
+	notification.ID = notificationID
+	notification.CreatedAt = time.Now()
+	publish(events.TypeNotification, []uint64{notification.UserID}, notification)
+}
+
+//notifyMentions notifies the users mentioned by nick in the post
+func notifyMentions(db *sql.DB, post models.Post, nicks []string) {
+	repository := repositories.NewUserRespository(db)
+	userIDs, error := repository.FetchIDsByNicks(nicks)
+	if error != nil {
+		log.Printf("could not find the users mentioned in post %d: %v", post.ID, error)
+		return
+	}
+	for _, userID := range userIDs {
+		notify(db, models.Notification{
+			UserID:  userID,
+			ActorID: post.AuthorID,
+			Type:    models.NotificationMention,
+			PostID:  post.ID,
+		})
+	}
+}
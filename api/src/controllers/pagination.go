@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+//pagination reads the page and limit query parameters and returns the limit and offset to be used in the queries
+func pagination(r *http.Request) (uint64, uint64, error) {
+	query := r.URL.Query()
+
+	page := uint64(1)
+	if value := query.Get("page"); value != "" {
+		parsedPage, error := strconv.ParseUint(value, 10, 64)
+		if error != nil || parsedPage == 0 {
+			return 0, 0, errors.New("The page must be a positive number")
+		}
+		page = parsedPage
+	}
+
+	limit := uint64(defaultPageSize)
+	if value := query.Get("limit"); value != "" {
+		parsedLimit, error := strconv.ParseUint(value, 10, 64)
+		if error != nil || parsedLimit == 0 {
+			return 0, 0, errors.New("The limit must be a positive number")
+		}
+		limit = parsedLimit
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	return limit, (page - 1) * limit, nil
+}
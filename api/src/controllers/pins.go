@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//PinPost pins a published post of the user to their profile
+func PinPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	post, error := repositories.NewPostRepository(db).FetchByID(postID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+	if post.AuthorID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("You can't pin a post that is not yours"))
+		return
+	}
+	if post.Status != models.StatusPublished {
+		responses.Error(w, http.StatusBadRequest, errors.New("Only published posts can be pinned"))
+		return
+	}
+
+	pinned, error := repositories.NewPinRepository(db).Pin(userID, postID, models.MaxPinnedPosts)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !pinned {
+		responses.Error(w, http.StatusBadRequest, fmt.Errorf("You can't pin more than %d posts", models.MaxPinnedPosts))
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//UnpinPost removes a post from the pinned posts of the user
+func UnpinPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	if error = repositories.NewPinRepository(db).Unpin(userID, postID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//ReorderPinnedPosts sets the order the pinned posts of the user are shown in, the body must list all of them
+func ReorderPinnedPosts(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+	var order models.PinOrder
+	if error = json.Unmarshal(requestBody, &order); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	reordered, error := repositories.NewPinRepository(db).Reorder(userID, order.PostIDs)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !reordered {
+		responses.Error(w, http.StatusBadRequest, errors.New("The posts must be the ones you pinned, each of them once"))
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
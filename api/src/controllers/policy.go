@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"api/src/models"
+	"api/src/policy"
+	"api/src/repositories"
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+//checkPolicy runs the content policy on a post being created or edited. Rejected posts come back with an error
+//and the status code to answer with, held posts with the hold decision
+func checkPolicy(repository *repositories.Posts, post models.Post) (policy.Decision, int, error) {
+	decision, error := policy.Default.Check(policy.Submission{
+		PostID:   post.ID,
+		AuthorID: post.AuthorID,
+		Title:    post.Title,
+		Content:  post.Content,
+	}, repository)
+	if error != nil {
+		return policy.Decision{}, http.StatusInternalServerError, error
+	}
+	if decision.Outcome == policy.Reject {
+		return policy.Decision{}, http.StatusUnprocessableEntity, errors.New(decision.Reason)
+	}
+	return decision, http.StatusOK, nil
+}
+
+//releasePost ends the hold of a post after a moderator dismissed its report. Drafts and scheduled posts go back
+//to their author, the others are published and announced
+func releasePost(db *sql.DB, postID uint64) error {
+	repository := repositories.NewPostRepository(db)
+	status, error := repository.Release(postID)
+	if error != nil || status != models.StatusPublished {
+		return error
+	}
+	return announcePublishedPost(repository, db, postID)
+}
@@ -3,19 +3,28 @@ package controllers
 import (
 	"api/src/authentication"
 	"api/src/base"
+	"api/src/events"
+	"api/src/markdown"
 	"api/src/models"
+	"api/src/policy"
 	"api/src/repositories"
 	"api/src/responses"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-// CreatePost creates a new post
+// exploreWindow is how old the posts of the explore feed can be
+const exploreWindow = 7 * 24 * time.Hour
+
+// CreatePost creates a new post, it is kept as a draft or scheduled when asked to
 func CreatePost(w http.ResponseWriter, r *http.Request) {
 	userID, error := authentication.ExtractUserID(r)
 	if error != nil {
@@ -33,30 +42,85 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	post.AuthorID = userID
+	if error = post.Prepare(); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
 	db, error := base.Connect()
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
 	defer db.Close()
+	suspended, error := repositories.NewUserRespository(db).IsSuspended(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if suspended {
+		responses.Error(w, http.StatusForbidden, errors.New("This account is suspended"))
+		return
+	}
 	repository := repositories.NewPostRepository(db)
+	decision, status, error := checkPolicy(repository, post)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	if decision.Outcome == policy.Hold {
+		post.HeldStatus = post.Status
+		post.HeldReason = decision.Reason
+		post.Status = models.StatusHeld
+	}
+	if post.QuoteOfID != 0 {
+		quotedPost, error := repository.FetchShareable(post.QuoteOfID, userID)
+		if error != nil {
+			responses.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+		if quotedPost.ID == 0 {
+			responses.Error(w, http.StatusNotFound, errors.New("The quoted post doesn't exist or can't be shared"))
+			return
+		}
+	}
+	if post.ParentID != 0 {
+		parent, error := repository.FetchVisible(post.ParentID, userID)
+		if error != nil {
+			responses.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+		if parent.ID == 0 {
+			responses.Error(w, http.StatusNotFound, errors.New("The post you are replying to doesn't exist"))
+			return
+		}
+	}
 	post.ID, error = repository.Create(post)
 
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	linkPreviews(db, post)
+	post.ContentHTML = markdown.Render(post.Content)
+	if post.Status == models.StatusPublished {
+		announcePost(db, post)
+	}
 
 	responses.JSON(w, http.StatusCreated, post)
 }
 
-// FetchPosts fetches all posts
+// FetchPosts fetches the timeline of the user
 func FetchPosts(w http.ResponseWriter, r *http.Request) {
 	userID, error := authentication.ExtractUserID(r)
 	if error != nil {
 		responses.Error(w, http.StatusUnauthorized, error)
 		return
 	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
 	db, error := base.Connect()
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
@@ -64,18 +128,60 @@ func FetchPosts(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 	repository := repositories.NewPostRepository(db)
-	posts, error := repository.Fetch(userID)
+	posts, error := repository.Fetch(userID, limit, offset)
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
 
+	if error = completePosts(db, userID, postReferences(posts)...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	recordImpressions(db, userID, posts)
 	responses.JSON(w, http.StatusOK, posts)
 
 }
 
+// FetchExplore fetches the public posts of the last days with the most recent likes, replies and reposts
+func FetchExplore(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+	repository := repositories.NewPostRepository(db)
+	posts, error := repository.FetchExplore(userID, time.Now().Add(-exploreWindow), limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if error = completePosts(db, userID, postReferences(posts)...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	recordImpressions(db, userID, posts)
+	responses.JSON(w, http.StatusOK, posts)
+}
+
 // FetchPost fetches a single post
 func FetchPost(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	parameters := mux.Vars(r)
 	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
 	if error != nil {
@@ -89,15 +195,77 @@ func FetchPost(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 	repository := repositories.NewPostRepository(db)
-	post, error := repository.FetchByID(postID)
+	post, error := repository.FetchVisible(postID, viewerID)
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+	if error = completePosts(db, viewerID, &post); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
 	responses.JSON(w, http.StatusOK, post)
 
 }
 
+// FetchThread fetches a post with the posts it replies to and a page of its replies
+func FetchThread(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+	repository := repositories.NewPostRepository(db)
+	post, error := repository.FetchVisible(postID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+	ancestors, error := repository.FetchAncestors(postID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	post.Children, error = repository.FetchReplies(postID, viewerID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	thread := models.Thread{Ancestors: ancestors, Post: post}
+	references := append(postReferences(thread.Ancestors), &thread.Post)
+	references = append(references, postReferences(thread.Post.Children)...)
+	if error = completePosts(db, viewerID, references...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, thread)
+}
+
 // UpdatePost updates a post
 func UpdatePost(w http.ResponseWriter, r *http.Request) {
 	userID, error := authentication.ExtractUserID(r)
@@ -144,16 +312,36 @@ func UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if post.Visibility == "" {
+		post.Visibility = postSavedInDatabase.Visibility
+	}
 	if error = post.Prepare(); error != nil {
 		responses.Error(w, http.StatusBadRequest, error)
 		return
 	}
+	post.ID = postID
+	post.AuthorID = userID
+	decision, status, error := checkPolicy(repository, post)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
 
 	if error = repository.Update(postID, post); error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
 
+	if decision.Outcome == policy.Hold && postSavedInDatabase.Status != models.StatusHeld {
+		if error = repository.Hold(postID, decision.Reason); error != nil {
+			responses.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+	} else if postSavedInDatabase.Status == models.StatusPublished {
+		notifyMentions(db, post, newMentions(postSavedInDatabase.Content, post.Content))
+	}
+	linkPreviews(db, post)
+
 	responses.JSON(w, http.StatusNoContent, nil)
 
 }
@@ -190,16 +378,29 @@ func DeletePost(w http.ResponseWriter, r *http.Request) {
 		responses.Error(w, http.StatusForbidden, errors.New("You can't delete a post that is not yours"))
 		return
 	}
+	attachments, error := repositories.NewAttachmentRepository(db).FetchByPosts([]uint64{postID})
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
 	if error = repository.Delete(postID); error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	for _, attachment := range attachments[postID] {
+		deleteFiles(attachment.Key, attachment.ThumbnailKey)
+	}
 	responses.JSON(w, http.StatusNoContent, nil)
 
 }
 
 // FetchPostByUser fetches all posts by a user
 func FetchPostByUser(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	parameters := mux.Vars(r)
 	userID, error := strconv.ParseUint(parameters["userID"], 10, 64)
 	if error != nil {
@@ -213,17 +414,27 @@ func FetchPostByUser(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 	repository := repositories.NewPostRepository(db)
-	posts, error := repository.FetchPostByUser(userID)
+	posts, error := repository.FetchPostByUser(userID, viewerID)
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	if error = completePosts(db, viewerID, postReferences(posts)...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	recordImpressions(db, viewerID, posts)
 	responses.JSON(w, http.StatusOK, posts)
 
 }
 
-// LikePost likes a post
+// LikePost likes a post, liking it again has no effect
 func LikePost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	parameters := mux.Vars(r)
 	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
 	if error != nil {
@@ -237,15 +448,82 @@ func LikePost(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 	repository := repositories.NewPostRepository(db)
-	if error = repository.Like(postID); error != nil {
+	post, error := repository.FetchVisible(postID, userID)
+	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+	liked, first, error := repository.Like(postID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if first {
+		recordActivity(db, models.Activity{ActorID: userID, Type: models.ActivityLike, UserID: post.AuthorID, PostID: postID})
+		notify(db, models.Notification{
+			UserID:  post.AuthorID,
+			ActorID: userID,
+			Type:    models.NotificationLike,
+			PostID:  postID,
+		})
+	}
+	if liked {
+		publishLikes(db, postID)
+	}
 	responses.JSON(w, http.StatusNoContent, nil)
 }
 
-// DislikePost removes the like from a post
+// DislikePost removes the like from a post the user can see
 func DislikePost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+	repository := repositories.NewPostRepository(db)
+	post, error := repository.FetchVisible(postID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+	disliked, error := repository.Dislike(postID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if disliked {
+		publishLikes(db, postID)
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// RepostPost shares a post with the followers of the user
+func RepostPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	parameters := mux.Vars(r)
 	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
 	if error != nil {
@@ -259,9 +537,77 @@ func DislikePost(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 	repository := repositories.NewPostRepository(db)
-	if error = repository.Dislike(postID); error != nil {
+	post, error := repository.FetchShareable(postID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("The post doesn't exist or can't be shared"))
+		return
+	}
+	if error = repository.Repost(postID, userID); error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
 	responses.JSON(w, http.StatusNoContent, nil)
 }
+
+// UnrepostPost undoes the repost of a post
+func UnrepostPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+	repository := repositories.NewPostRepository(db)
+	if error = repository.Unrepost(postID, userID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//publishLikes pushes the current like count of the post to its author and their followers
+func publishLikes(db *sql.DB, postID uint64) {
+	repository := repositories.NewPostRepository(db)
+	post, error := repository.FetchByID(postID)
+	if error != nil {
+		log.Printf("could not fetch post %d: %v", postID, error)
+		return
+	}
+	publishToFollowers(db, post.AuthorID, events.TypeLikes, struct {
+		PostID uint64 `json:"postID"`
+		Likes  uint64 `json:"likes"`
+	}{
+		PostID: post.ID,
+		Likes:  post.Likes,
+	}, true)
+}
+
+//newMentions returns the nicks mentioned in the updated content that were not in the previous one
+func newMentions(previousContent, content string) []string {
+	previous := map[string]bool{}
+	for _, nick := range models.ParseMentions(previousContent) {
+		previous[nick] = true
+	}
+	var nicks []string
+	for _, nick := range models.ParseMentions(content) {
+		if !previous[nick] {
+			nicks = append(nicks, nick)
+		}
+	}
+	return nicks
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"api/src/base"
+	"api/src/models"
+	"api/src/previews"
+	"api/src/repositories"
+	"database/sql"
+	"log"
+)
+
+// pendingPreviewsOnStart is how many links left pending by a previous run are queued on start
+const pendingPreviewsOnStart = 200
+
+//linkPreviews records the links of the post and queues the ones without a preview.
+//Failures are only logged because the post was already saved
+func linkPreviews(db *sql.DB, post models.Post) {
+	repository := repositories.NewLinkPreviewRepository(db)
+	pending, error := repository.Link(post.ID, previews.ExtractURLs(post.Content))
+	if error != nil {
+		log.Printf("could not link the previews of post %d: %v", post.ID, error)
+		return
+	}
+	previews.Enqueue(pending...)
+}
+
+//SaveLinkPreview stores the result of fetching a link, it is called by the preview worker
+func SaveLinkPreview(link string, preview models.LinkPreview, fetchError error) {
+	db, error := base.Connect()
+	if error != nil {
+		log.Printf("could not save the preview of %s: %v", link, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewLinkPreviewRepository(db)
+	if fetchError != nil {
+		error = repository.MarkFailed(link)
+	} else {
+		error = repository.Save(preview)
+	}
+	if error != nil {
+		log.Printf("could not save the preview of %s: %v", link, error)
+	}
+}
+
+//EnqueuePendingPreviews queues the links whose preview was not fetched before the API stopped
+func EnqueuePendingPreviews() {
+	db, error := base.Connect()
+	if error != nil {
+		log.Printf("could not queue the pending link previews: %v", error)
+		return
+	}
+	defer db.Close()
+
+	links, error := repositories.NewLinkPreviewRepository(db).FetchPending(pendingPreviewsOnStart)
+	if error != nil {
+		log.Printf("could not queue the pending link previews: %v", error)
+		return
+	}
+	previews.Enqueue(links...)
+}
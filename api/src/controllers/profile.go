@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/media"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"api/src/storage"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//avatarSizes are the square sizes, in pixels, every avatar is resized to
+var avatarSizes = map[string]int{
+	"small":  48,
+	"medium": 128,
+	"large":  400,
+}
+
+const (
+	bannerWidth  = 1500
+	bannerHeight = 500
+)
+
+//UploadAvatar replaces the avatar of the user, the image must be sent in the file field of a multipart form
+func UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userIDInToken != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("It is not possible to change the avatar of another user"))
+		return
+	}
+
+	content, status, error := readUpload(w, r)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	key, error := storeAvatar(userID, content)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		deleteFiles(avatarKeys(key)...)
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	previousKey, error := repository.UpdateAvatar(userID, key)
+	if error != nil {
+		deleteFiles(avatarKeys(key)...)
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	deleteFiles(avatarKeys(previousKey)...)
+
+	user := models.User{AvatarKey: key}
+	setUserImageURLs(&user)
+	responses.JSON(w, http.StatusOK, user.Avatar)
+}
+
+//DeleteAvatar removes the avatar of the user
+func DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userIDInToken != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("It is not possible to change the avatar of another user"))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	previousKey, error := repository.UpdateAvatar(userID, "")
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	deleteFiles(avatarKeys(previousKey)...)
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//UploadBanner replaces the banner of the user, the image must be sent in the file field of a multipart form
+func UploadBanner(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userIDInToken != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("It is not possible to change the banner of another user"))
+		return
+	}
+
+	content, status, error := readUpload(w, r)
+	if error != nil {
+		responses.Error(w, status, error)
+		return
+	}
+	key, error := storeBanner(userID, content)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		deleteFiles(key)
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	previousKey, error := repository.UpdateBanner(userID, key)
+	if error != nil {
+		deleteFiles(key)
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	deleteFiles(previousKey)
+
+	responses.JSON(w, http.StatusOK, map[string]string{"banner": storage.Default.URL(key)})
+}
+
+//DeleteBanner removes the banner of the user
+func DeleteBanner(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userIDInToken != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("It is not possible to change the banner of another user"))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	previousKey, error := repository.UpdateBanner(userID, "")
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	deleteFiles(previousKey)
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//storeAvatar crops the image to a square in every avatar size and saves them, returning the key they share
+func storeAvatar(userID uint64, content []byte) (string, error) {
+	decodedImage, error := decodeProfileImage(content)
+	if error != nil {
+		return "", error
+	}
+	name, error := randomName()
+	if error != nil {
+		return "", error
+	}
+	key := fmt.Sprintf("avatars/%d/%s", userID, name)
+
+	for _, size := range avatarSizes {
+		resized, error := media.EncodeJPEG(media.Fill(decodedImage, size, size))
+		if error != nil {
+			deleteFiles(avatarKeys(key)...)
+			return "", error
+		}
+		if error = storage.Default.Save(avatarKey(key, size), resized, "image/jpeg"); error != nil {
+			deleteFiles(avatarKeys(key)...)
+			return "", error
+		}
+	}
+	return key, nil
+}
+
+//storeBanner crops the image to the banner size and saves it
+func storeBanner(userID uint64, content []byte) (string, error) {
+	decodedImage, error := decodeProfileImage(content)
+	if error != nil {
+		return "", error
+	}
+	name, error := randomName()
+	if error != nil {
+		return "", error
+	}
+	key := fmt.Sprintf("banners/%d/%s.jpg", userID, name)
+
+	resized, error := media.EncodeJPEG(media.Fill(decodedImage, bannerWidth, bannerHeight))
+	if error != nil {
+		return "", error
+	}
+	if error = storage.Default.Save(key, resized, "image/jpeg"); error != nil {
+		return "", error
+	}
+	return key, nil
+}
+
+//decodeProfileImage checks the real type of the upload before decoding it, only images are accepted
+func decodeProfileImage(content []byte) (image.Image, error) {
+	contentType := http.DetectContentType(content)
+	if !media.Decodable[contentType] {
+		return nil, fmt.Errorf("Files of type %s can't be used as a profile image", contentType)
+	}
+	decodedImage, error := media.Decode(content)
+	if error != nil {
+		return nil, fmt.Errorf("The image could not be read: %v", error)
+	}
+	return decodedImage, nil
+}
+
+//avatarKey returns the key of the file of the avatar in the given size
+func avatarKey(key string, size int) string {
+	return fmt.Sprintf("%s-%d.jpg", key, size)
+}
+
+//avatarKeys returns the keys of the files of the avatar in every size
+func avatarKeys(key string) []string {
+	if key == "" {
+		return nil
+	}
+	var keys []string
+	for _, size := range avatarSizes {
+		keys = append(keys, avatarKey(key, size))
+	}
+	return keys
+}
+
+//setUserImageURLs turns the storage keys of the avatar and the banner of the user into URLs
+func setUserImageURLs(user *models.User) {
+	if user.AvatarKey != "" {
+		user.Avatar = make(map[string]string, len(avatarSizes))
+		for name, size := range avatarSizes {
+			user.Avatar[name] = storage.Default.URL(avatarKey(user.AvatarKey, size))
+		}
+	}
+	if user.BannerKey != "" {
+		user.Banner = storage.Default.URL(user.BannerKey)
+	}
+}
+
+func setUsersImageURLs(users []models.User) {
+	for i := range users {
+		setUserImageURLs(&users[i])
+	}
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"api/src/base"
+	"api/src/ranking"
+	"api/src/repositories"
+	"log"
+)
+
+//RescorePosts computes again the scores of the explore feed when they were stored with another half life,
+//it runs on start as the scores of new engagements would not be comparable with the ones stored
+func RescorePosts() {
+	db, error := base.Connect()
+	if error != nil {
+		log.Printf("could not check the half life of the scores: %v", error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewRankingRepository(db)
+	scored, error := repository.ScoredWith(ranking.HalfLife)
+	if error != nil {
+		log.Printf("could not check the half life of the scores: %v", error)
+		return
+	}
+	if scored {
+		return
+	}
+	rescored, error := repository.Rescore()
+	if error != nil {
+		log.Printf("could not rescore the posts with a half life of %v: %v", ranking.HalfLife, error)
+		return
+	}
+	log.Printf("rescored %d posts with a half life of %v", rescored, ranking.HalfLife)
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+//FetchRelationship fetches how the user and the viewer are connected
+func FetchRelationship(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	userID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	relationships, error := repositories.NewUserRespository(db).FetchRelationships(viewerID, []uint64{userID})
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if len(relationships) == 0 || relationships[0].BlockedBy {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+	responses.JSON(w, http.StatusOK, relationships[0])
+}
+
+//FetchRelationships fetches how the viewer is connected to the users in the comma separated ids parameter.
+//Users that don't exist or blocked the viewer are left out
+func FetchRelationships(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	userIDs, error := parseIDs(r.URL.Query().Get("ids"))
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if len(userIDs) > models.MaxRelationshipsPerRequest {
+		responses.Error(w, http.StatusBadRequest, fmt.Errorf("You can't ask for more than %d users at once", models.MaxRelationshipsPerRequest))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	relationships, error := repositories.NewUserRespository(db).FetchRelationships(viewerID, userIDs)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	visible := []models.Relationship{}
+	for _, relationship := range relationships {
+		if !relationship.BlockedBy {
+			visible = append(visible, relationship)
+		}
+	}
+	responses.JSON(w, http.StatusOK, visible)
+}
+
+//FetchMutuals fetches the followers of the user that the viewer also follows
+func FetchMutuals(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	userID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(userID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+
+	mutuals, error := repositories.NewUserRespository(db).FetchMutuals(userID, viewerID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	setUsersImageURLs(mutuals)
+	responses.JSON(w, http.StatusOK, mutuals)
+}
+
+//parseIDs reads a comma separated list of IDs, ignoring repeated ones
+func parseIDs(value string) ([]uint64, error) {
+	var IDs []uint64
+	seen := map[uint64]bool{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		ID, error := strconv.ParseUint(field, 10, 64)
+		if error != nil {
+			return nil, errors.New("The ids must be a comma separated list of numbers")
+		}
+		if !seen[ID] {
+			seen[ID] = true
+			IDs = append(IDs, ID)
+		}
+	}
+	return IDs, nil
+}
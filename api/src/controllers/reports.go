@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//ReportPost flags a post the user can see for the moderators
+func ReportPost(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	report, error := readReport(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	post, error := repositories.NewPostRepository(db).FetchVisible(postID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+	if post.AuthorID == userID {
+		responses.Error(w, http.StatusBadRequest, errors.New("You can't report your own post"))
+		return
+	}
+
+	report.ReporterID = userID
+	report.UserID = post.AuthorID
+	report.PostID = post.ID
+	report.ID, error = repositories.NewReportRepository(db).Create(report)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusCreated, report)
+}
+
+//ReportUser flags a user for the moderators
+func ReportUser(w http.ResponseWriter, r *http.Request) {
+	reporterID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	paramenters := mux.Vars(r)
+	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if reporterID == userID {
+		responses.Error(w, http.StatusBadRequest, errors.New("You can't report yourself"))
+		return
+	}
+
+	report, error := readReport(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	exists, error := repositories.NewUserRespository(db).Exists(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !exists {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+
+	report.ReporterID = reporterID
+	report.UserID = userID
+	report.ID, error = repositories.NewReportRepository(db).Create(report)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusCreated, report)
+}
+
+//FetchReports fetches the moderation queue, the open reports unless another status is asked for
+func FetchReports(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.ReportOpen
+	case models.ReportOpen, models.ReportClaimed, models.ReportResolved:
+	default:
+		responses.Error(w, http.StatusBadRequest, errors.New("Status must be open, claimed or resolved"))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	if code, error := checkModerator(db, userID); error != nil {
+		responses.Error(w, code, error)
+		return
+	}
+
+	reports, error := repositories.NewReportRepository(db).Fetch(status, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, reports)
+}
+
+//ClaimReport assigns a report to the moderator so others don't work on it
+func ClaimReport(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	reportID, error := strconv.ParseUint(parameters["reportID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	if code, error := checkModerator(db, userID); error != nil {
+		responses.Error(w, code, error)
+		return
+	}
+
+	claimed, error := repositories.NewReportRepository(db).Claim(reportID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !claimed {
+		responses.Error(w, http.StatusConflict, errors.New("The report doesn't exist, is resolved or was claimed by another moderator"))
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//ResolveReport closes a report with an action: dismissing it, hiding the reported post or suspending its author,
+//and lets the reporter know it was handled. Dismissing a report of the content policy releases the held post
+func ResolveReport(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	reportID, error := strconv.ParseUint(parameters["reportID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+	var resolution models.Resolution
+	if error = json.Unmarshal(requestBody, &resolution); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if error = resolution.Validate(); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	if code, error := checkModerator(db, userID); error != nil {
+		responses.Error(w, code, error)
+		return
+	}
+
+	repository := repositories.NewReportRepository(db)
+	report, error := repository.FetchByID(reportID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if report.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Report not found"))
+		return
+	}
+	if resolution.Action == models.ActionHidePost && report.PostID == 0 {
+		responses.Error(w, http.StatusBadRequest, errors.New("The report is not about a post"))
+		return
+	}
+
+	resolved, error := repository.Resolve(report, userID, resolution.Action)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if !resolved {
+		responses.Error(w, http.StatusConflict, errors.New("The report is resolved or was claimed by another moderator"))
+		return
+	}
+	if report.Reason == models.ReasonPolicy && resolution.Action == models.ActionDismiss {
+		if error = releasePost(db, report.PostID); error != nil {
+			responses.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+	}
+	if report.ReporterID != 0 {
+		notify(db, models.Notification{
+			UserID: report.ReporterID,
+			Type:   models.NotificationReportResolved,
+			PostID: report.PostID,
+		})
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func readReport(r *http.Request) (models.Report, error) {
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		return models.Report{}, error
+	}
+	var report models.Report
+	if error = json.Unmarshal(requestBody, &report); error != nil {
+		return models.Report{}, error
+	}
+	if error = report.Prepare(); error != nil {
+		return models.Report{}, error
+	}
+	return report, nil
+}
+
+//checkModerator only lets moderators through, the returned status code tells the client what went wrong
+func checkModerator(db *sql.DB, userID uint64) (int, error) {
+	moderator, error := repositories.NewUserRespository(db).IsModerator(userID)
+	if error != nil {
+		return http.StatusInternalServerError, error
+	}
+	if !moderator {
+		return http.StatusForbidden, errors.New("Only moderators can do this")
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultStatsDays is how many days of stats are returned when the client doesn't ask for a number
+const defaultStatsDays = 30
+
+//FetchUserStats fetches the daily impressions, likes and new followers of the user, only they can see them
+func FetchUserStats(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	userID, error := strconv.ParseUint(parameters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userID != userIDInToken {
+		responses.Error(w, http.StatusForbidden, errors.New("You can only see your own stats"))
+		return
+	}
+	first, last, error := statsPeriod(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	days, error := repositories.NewStatsRepository(db).FetchAuthorStats(userID, first)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, models.NewStats(first, last, days))
+}
+
+//FetchPostStats fetches the daily impressions and likes of a post of the user
+func FetchPostStats(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+	postID, error := strconv.ParseUint(parameters["postID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	first, last, error := statsPeriod(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	post, error := repositories.NewPostRepository(db).FetchByID(postID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if post.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("Post not found"))
+		return
+	}
+	if post.AuthorID != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("You can only see the stats of your own posts"))
+		return
+	}
+
+	days, error := repositories.NewStatsRepository(db).FetchPostStats(postID, first)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, models.NewStats(first, last, days))
+}
+
+//statsPeriod reads the days query parameter and returns the first and the last day of the stats, the last one being today
+func statsPeriod(r *http.Request) (time.Time, time.Time, error) {
+	days := uint64(defaultStatsDays)
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsedDays, error := strconv.ParseUint(value, 10, 64)
+		if error != nil || parsedDays == 0 || parsedDays > models.MaxStatsDays {
+			return time.Time{}, time.Time{}, fmt.Errorf("The days must be a number between 1 and %d", models.MaxStatsDays)
+		}
+		days = parsedDays
+	}
+	now := time.Now()
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return last.AddDate(0, 0, 1-int(days)), last, nil
+}
+
+//recordImpressions counts the posts of a feed as seen by the viewer. Failures are only logged so the feed is still served
+func recordImpressions(db *sql.DB, viewerID uint64, posts []models.Post) {
+	if error := repositories.NewStatsRepository(db).RecordImpressions(viewerID, posts); error != nil {
+		log.Printf("could not record the impressions of user %d: %v", viewerID, error)
+	}
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/events"
+	"api/src/repositories"
+	"api/src/responses"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const streamHeartbeat = 30 * time.Second
+
+//Stream pushes the events of the user as Server-Sent Events until the client disconnects
+func Stream(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		responses.Error(w, http.StatusInternalServerError, errors.New("Streaming is not supported"))
+		return
+	}
+
+	stream, unsubscribe := events.Default.Subscribe(userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, error := fmt.Fprint(w, ": heartbeat\n\n"); error != nil {
+				return
+			}
+		case event := <-stream:
+			if _, error := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, event.Data); error != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+//publish sends an event to the streams of the recipients. Failures are only logged because
+//they must not undo the action that caused them
+func publish(eventType string, recipients []uint64, data interface{}) {
+	if error := events.Default.Publish(eventType, recipients, data); error != nil {
+		log.Printf("could not publish %s event: %v", eventType, error)
+	}
+}
+
+//publishToFollowers sends an event to the streams of the followers of the user who didn't mute them
+func publishToFollowers(db *sql.DB, userID uint64, eventType string, data interface{}, includeUser bool) {
+	repository := repositories.NewUserRespository(db)
+	followerIDs, error := repository.FetchFollowerIDs(userID)
+	if error != nil {
+		log.Printf("could not fetch the followers of user %d: %v", userID, error)
+		return
+	}
+	if includeUser {
+		followerIDs = append(followerIDs, userID)
+	}
+	publish(eventType, followerIDs, data)
+}
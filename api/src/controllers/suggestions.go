@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/repositories"
+	"api/src/responses"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// suggestionsMaxAge is how long the follow suggestions of a user are served before being computed again
+	suggestionsMaxAge = time.Hour
+	// staleSuggestionsBatch is how many users get their suggestions computed again at a time
+	staleSuggestionsBatch = 100
+)
+
+//FetchSuggestions fetches the users suggested for the user to follow, computing them first when they are missing or stale
+func FetchSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewSuggestionRepository(db)
+	stale, error := repository.IsStale(userID, time.Now().Add(-suggestionsMaxAge))
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if stale {
+		if error = repository.Refresh(userID); error != nil {
+			responses.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+	}
+
+	suggestions, error := repository.Fetch(userID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	for i := range suggestions {
+		setUserImageURLs(&suggestions[i].User)
+	}
+	responses.JSON(w, http.StatusOK, suggestions)
+}
+
+//RefreshSuggestions computes again the stale suggestions of the users who asked for them, it is run by the scheduler.
+//A user whose suggestions can't be refreshed doesn't stop the others, they keep their previous suggestions
+//and go to the end of the queue so the next runs move on to the other users
+func RefreshSuggestions() {
+	db, error := base.Connect()
+	if error != nil {
+		log.Printf("could not refresh the follow suggestions: %v", error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewSuggestionRepository(db)
+	userIDs, error := repository.FetchStaleUserIDs(time.Now().Add(-suggestionsMaxAge), staleSuggestionsBatch)
+	if error != nil {
+		log.Printf("could not refresh the follow suggestions: %v", error)
+		return
+	}
+	for _, userID := range userIDs {
+		if error = repository.Refresh(userID); error != nil {
+			log.Printf("could not refresh the follow suggestions of user %d: %v", userID, error)
+			if error = repository.Postpone(userID); error != nil {
+				log.Printf("could not postpone the follow suggestions of user %d: %v", userID, error)
+			}
+		}
+	}
+}
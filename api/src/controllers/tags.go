@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"api/src/authentication"
+	"api/src/base"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/responses"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultTrendingWindow = 24
+	maxTrendingWindow     = 24 * 7
+	trendingTagsLimit     = 10
+)
+
+//FetchTagPosts fetches the posts with a tag
+func FetchTagPosts(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	tag, error := tagFromRequest(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewPostRepository(db)
+	posts, error := repository.FetchByTag(tag, viewerID, limit, offset)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if error = completePosts(db, viewerID, postReferences(posts)...); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	recordImpressions(db, viewerID, posts)
+	responses.JSON(w, http.StatusOK, posts)
+}
+
+//FetchTrendingTags fetches the most used tags in the last hours, 24 by default
+func FetchTrendingTags(w http.ResponseWriter, r *http.Request) {
+	hours := uint64(defaultTrendingWindow)
+	if value := r.URL.Query().Get("hours"); value != "" {
+		parsedHours, error := strconv.ParseUint(value, 10, 64)
+		if error != nil || parsedHours == 0 || parsedHours > maxTrendingWindow {
+			responses.Error(w, http.StatusBadRequest, errors.New("The hours must be a number between 1 and 168"))
+			return
+		}
+		hours = parsedHours
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewTagRepository(db)
+	tags, error := repository.Trending(time.Now().Add(-time.Duration(hours)*time.Hour), trendingTagsLimit)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, tags)
+}
+
+//FetchFollowedTags fetches the tags the user follows
+func FetchFollowedTags(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewTagRepository(db)
+	tags, error := repository.FetchFollowed(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, tags)
+}
+
+//FollowTag makes the posts with a tag appear in the timeline of the user
+func FollowTag(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	tag, error := tagFromRequest(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewTagRepository(db)
+	if error = repository.Follow(tag, userID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+//UnfollowTag removes the posts with a tag from the timeline of the user
+func UnfollowTag(w http.ResponseWriter, r *http.Request) {
+	userID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	tag, error := tagFromRequest(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewTagRepository(db)
+	if error = repository.Unfollow(tag, userID); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+func tagFromRequest(r *http.Request) (string, error) {
+	tag, valid := models.NormalizeTag(mux.Vars(r)["tag"])
+	if !valid {
+		return "", errors.New("Invalid tag")
+	}
+	return tag, nil
+}
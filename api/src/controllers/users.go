@@ -54,10 +54,21 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 	responses.JSON(w, http.StatusCreated, user)
 }
 
-//FetchUsers fetches users
+//FetchUsers searches users by name or nick
 func FetchUsers(w http.ResponseWriter, r *http.Request) {
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	limit, offset, error := pagination(r)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
 
-	nameOrNick := strings.ToLower(r.URL.Query().Get("user"))
+	nameOrNick := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(r.URL.Query().Get("user"))), "@")
 	db, error := base.Connect()
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
@@ -66,16 +77,17 @@ func FetchUsers(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repository := repositories.NewUserRespository(db)
-	users, error := repository.Fetch(nameOrNick)
+	users, error := repository.Fetch(nameOrNick, viewerID, limit, offset)
 
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	setUsersImageURLs(users)
 	responses.JSON(w, http.StatusOK, users)
 }
 
-//FetchUser fetches a user
+//FetchUser fetches the profile of a user
 func FetchUser(w http.ResponseWriter, r *http.Request) {
 	paramenters := mux.Vars(r)
 	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
@@ -85,6 +97,12 @@ func FetchUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
 	db, error := base.Connect()
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
@@ -93,16 +111,22 @@ func FetchUser(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repository := repositories.NewUserRespository(db)
-	user, error := repository.FetchByID(userID)
+	profile, error := repository.FetchProfile(userID, viewerID)
 
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	responses.JSON(w, http.StatusOK, user)
+	if profile.ID == 0 {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+	setUserImageURLs(&profile.User)
+	responses.JSON(w, http.StatusOK, profile)
 }
 
-//UpdateUser updates a user
+//UpdateUser updates a user. Name, nick and email are required, bio, location and website
+//keep their stored value when they are left out of the body and are cleared when sent empty
 func UpdateUser(w http.ResponseWriter, r *http.Request) {
 	paramenters := mux.Vars(r)
 	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
@@ -125,26 +149,32 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		responses.Error(w, http.StatusUnprocessableEntity, error)
 		return
 	}
-	var user models.User
 
-	if error = json.Unmarshal(requestBody, &user); error != nil {
-		responses.Error(w, http.StatusBadRequest, error)
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	defer db.Close()
 
-	if error = user.Prepare("edition"); error != nil {
+	repository := repositories.NewUserRespository(db)
+	storedUser, error := repository.FetchByID(userID, userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	user := models.User{Bio: storedUser.Bio, Location: storedUser.Location, Website: storedUser.Website}
+	if error = json.Unmarshal(requestBody, &user); error != nil {
 		responses.Error(w, http.StatusBadRequest, error)
 		return
 	}
 
-	db, error := base.Connect()
-	if error != nil {
-		responses.Error(w, http.StatusInternalServerError, error)
+	if error = user.Prepare("edition"); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
 		return
 	}
-	defer db.Close()
 
-	repository := repositories.NewUserRespository(db)
 	if error = repository.Update(userID, user); error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
@@ -184,16 +214,22 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repository := repositories.NewUserRespository(db)
+	avatar, banner, error := repository.FetchImageKeys(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
 
 	if error = repository.Delete(userID); error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	deleteFiles(append(avatarKeys(avatar), banner)...)
 	responses.JSON(w, http.StatusNoContent, nil)
 
 }
 
-//FollowUser lets an user follow another
+//FollowUser lets an user follow another, following a private user only sends a follow request
 func FollowUser(w http.ResponseWriter, r *http.Request) {
 	followerID, error := authentication.ExtractUserID(r)
 
@@ -220,11 +256,56 @@ func FollowUser(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(userID, followerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusForbidden, errors.New("You can't follow this user"))
+		return
+	}
+
 	repository := repositories.NewUserRespository(db)
-	if error = repository.Follow(userID, followerID); error != nil {
+	private, error := repository.IsPrivate(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if private {
+		following, error := repository.IsFollowing(userID, followerID)
+		if error != nil {
+			responses.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+		if !following {
+			if error = repository.RequestFollow(userID, followerID); error != nil {
+				responses.Error(w, http.StatusInternalServerError, error)
+				return
+			}
+			notify(db, models.Notification{
+				UserID:  userID,
+				ActorID: followerID,
+				Type:    models.NotificationFollowRequest,
+			})
+			responses.JSON(w, http.StatusAccepted, nil)
+			return
+		}
+	}
+
+	followed, error := repository.Follow(userID, followerID)
+	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	if followed {
+		recordActivity(db, models.Activity{ActorID: followerID, Type: models.ActivityFollow, UserID: userID})
+		notify(db, models.Notification{
+			UserID:  userID,
+			ActorID: followerID,
+			Type:    models.NotificationFollow,
+		})
+	}
 	responses.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -271,6 +352,12 @@ func FetchFollowers(w http.ResponseWriter, r *http.Request) {
 		responses.Error(w, http.StatusBadRequest, error)
 		return
 	}
+
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	db, error := base.Connect()
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
@@ -278,14 +365,25 @@ func FetchFollowers(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(userID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+
 	repository := repositories.NewUserRespository(db)
-	followers, error := repository.FetchFollowers(userID)
+	followers, error := repository.FetchFollowers(userID, viewerID)
 
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
 
+	setUsersImageURLs(followers)
 	responses.JSON(w, http.StatusOK, followers)
 }
 
@@ -298,6 +396,12 @@ func FetchFollowing(w http.ResponseWriter, r *http.Request) {
 		responses.Error(w, http.StatusBadRequest, error)
 		return
 	}
+
+	viewerID, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	db, error := base.Connect()
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
@@ -305,13 +409,24 @@ func FetchFollowing(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	blocked, error := repositories.NewBlockRepository(db).IsBlocked(userID, viewerID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if blocked {
+		responses.Error(w, http.StatusNotFound, errors.New("User not found"))
+		return
+	}
+
 	repository := repositories.NewUserRespository(db)
-	users, error := repository.FetchFollowing(userID)
+	users, error := repository.FetchFollowing(userID, viewerID)
 
 	if error != nil {
 		responses.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	setUsersImageURLs(users)
 
 	responses.JSON(w, http.StatusOK, users)
 }
@@ -374,3 +489,93 @@ func UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	}
 	responses.JSON(w, http.StatusNoContent, nil)
 }
+
+//FetchSettings fetches the privacy settings of the user
+func FetchSettings(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	paramenters := mux.Vars(r)
+	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userIDInToken != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("It is not possible to see the settings of another user"))
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	settings, error := repository.FetchSettings(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	responses.JSON(w, http.StatusOK, settings)
+}
+
+//UpdateSettings changes the privacy settings of the user, the settings left out of the body keep their value.
+//Making the account public approves its pending follow requests
+func UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userIDInToken, error := authentication.ExtractUserID(r)
+	if error != nil {
+		responses.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	paramenters := mux.Vars(r)
+	userID, error := strconv.ParseUint(paramenters["userID"], 10, 64)
+	if error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if userIDInToken != userID {
+		responses.Error(w, http.StatusForbidden, errors.New("It is not possible to update the settings of another user"))
+		return
+	}
+
+	requestBody, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	db, error := base.Connect()
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	defer db.Close()
+
+	repository := repositories.NewUserRespository(db)
+	storedSettings, error := repository.FetchSettings(userID)
+	if error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	settings := storedSettings
+	if error = json.Unmarshal(requestBody, &settings); error != nil {
+		responses.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	if error = repository.UpdateSettings(userID, settings); error != nil {
+		responses.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	if storedSettings.Private && !settings.Private {
+		approveFollowRequests(db, userID)
+	}
+	responses.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Types of event
+const (
+	TypePost         = "post"
+	TypeNotification = "notification"
+	TypeLikes        = "likes"
+	TypeMessage      = "message"
+)
+
+// streamBuffer is how many events a slow stream holds before new ones are dropped
+const streamBuffer = 16
+
+// Event is something that happened and must be pushed to its recipients
+type Event struct {
+	Type       string          `json:"type"`
+	Recipients []uint64        `json:"recipients"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Broker carries the events between the hubs. The local broker only reaches the hub of this
+// process, a broker backed by a message queue lets multiple API instances share the events
+type Broker interface {
+	Publish(event Event) error
+	Subscribe(handler func(Event))
+}
+
+// Hub keeps the event streams of the connected users
+type Hub struct {
+	broker  Broker
+	mutex   sync.RWMutex
+	streams map[uint64]map[chan Event]struct{}
+}
+
+// Default is the hub used by the API
+var Default = NewHub(NewLocalBroker())
+
+//NewHub creates a hub that receives the events from the broker
+func NewHub(broker Broker) *Hub {
+	hub := &Hub{
+		broker:  broker,
+		streams: map[uint64]map[chan Event]struct{}{},
+	}
+	broker.Subscribe(hub.deliver)
+	return hub
+}
+
+//Publish sends an event to the recipients through the broker
+func (hub *Hub) Publish(eventType string, recipients []uint64, data interface{}) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	encodedData, error := json.Marshal(data)
+	if error != nil {
+		return error
+	}
+	return hub.broker.Publish(Event{Type: eventType, Recipients: recipients, Data: encodedData})
+}
+
+//Subscribe opens a stream with the events of the user, the returned function closes it
+func (hub *Hub) Subscribe(userID uint64) (<-chan Event, func()) {
+	stream := make(chan Event, streamBuffer)
+
+	hub.mutex.Lock()
+	if hub.streams[userID] == nil {
+		hub.streams[userID] = map[chan Event]struct{}{}
+	}
+	hub.streams[userID][stream] = struct{}{}
+	hub.mutex.Unlock()
+
+	return stream, func() {
+		hub.mutex.Lock()
+		defer hub.mutex.Unlock()
+		delete(hub.streams[userID], stream)
+		if len(hub.streams[userID]) == 0 {
+			delete(hub.streams, userID)
+		}
+	}
+}
+
+//deliver pushes the event to the open streams of its recipients without waiting for slow readers
+func (hub *Hub) deliver(event Event) {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+	for _, userID := range event.Recipients {
+		for stream := range hub.streams[userID] {
+			select {
+			case stream <- event:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package events
+
+import "sync"
+
+// LocalBroker delivers the events to the hubs of this process
+type LocalBroker struct {
+	mutex    sync.RWMutex
+	handlers []func(Event)
+}
+
+//NewLocalBroker creates an in-process broker
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{}
+}
+
+//Publish hands the event to every subscribed handler
+func (broker *LocalBroker) Publish(event Event) error {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+	for _, handler := range broker.handlers {
+		handler(event)
+	}
+	return nil
+}
+
+//Subscribe registers a handler for the published events
+func (broker *LocalBroker) Subscribe(handler func(Event)) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.handlers = append(broker.handlers, handler)
+}
@@ -0,0 +1,206 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// maxQuoteDepth stops deeply nested block quotes from recursing without end
+const maxQuoteDepth = 3
+
+var (
+	fence         = regexp.MustCompile("^ {0,3}(```+|~~~+)\\s*([^`\\s]*)")
+	bulletItem    = regexp.MustCompile(`^ {0,3}[-*+]\s+(.*)$`)
+	orderedItem   = regexp.MustCompile(`^ {0,3}(\d{1,9})[.)]\s+(.*)$`)
+	quoteLine     = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	inlineLink    = regexp.MustCompile(`^\[([^\[\]]+)\]\(([^()\s]+)\)`)
+	bareLink      = regexp.MustCompile(`^https?://[^\s<>]+`)
+	strongEm      = regexp.MustCompile(`\*\*\*(\S(?:.*?\S)?)\*\*\*`)
+	strong        = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	strikethrough = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	starEmphasis  = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+	lowEmphasis   = regexp.MustCompile(`(^|[^\p{L}\p{N}_])_(\S(?:.*?\S)?)_($|[^\p{L}\p{N}_])`)
+	languageName  = regexp.MustCompile(`^[a-z0-9_+#.-]{1,20}$`)
+)
+
+//Render turns the supported Markdown subset into sanitized HTML: paragraphs, line breaks,
+//fenced code blocks with a language hint, inline code, links, lists, block quotes and emphasis.
+//Raw HTML in the source is always escaped
+func Render(source string) string {
+	source = strings.ReplaceAll(strings.ReplaceAll(source, "\r\n", "\n"), "\r", "\n")
+	return Sanitize(renderBlocks(strings.Split(source, "\n"), 0))
+}
+
+func renderBlocks(lines []string, depth int) string {
+	var output strings.Builder
+	var paragraph []string
+
+	closeParagraph := func() {
+		if len(paragraph) > 0 {
+			output.WriteString("<p>" + renderInline(strings.Join(paragraph, "\n"), true) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if match := fence.FindStringSubmatch(line); match != nil {
+			closeParagraph()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), match[1]); i++ {
+				code = append(code, lines[i])
+			}
+			output.WriteString(codeBlock(strings.Join(code, "\n"), match[2]))
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			closeParagraph()
+			continue
+		}
+
+		if quoteLine.MatchString(line) && depth < maxQuoteDepth {
+			closeParagraph()
+			var quoted []string
+			for ; i < len(lines) && quoteLine.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteLine.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			output.WriteString("<blockquote>\n" + renderBlocks(quoted, depth+1) + "</blockquote>\n")
+			continue
+		}
+
+		if bulletItem.MatchString(line) || orderedItem.MatchString(line) {
+			closeParagraph()
+			var list string
+			list, i = renderList(lines, i)
+			output.WriteString(list)
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+	}
+	closeParagraph()
+	return output.String()
+}
+
+//renderList renders the list starting at the given line, returning the index of its last line.
+//Lines indented below an item continue it
+func renderList(lines []string, start int) (string, int) {
+	ordered := orderedItem.MatchString(lines[start])
+	item := bulletItem
+	tag := "ul"
+	if ordered {
+		item = orderedItem
+		tag = "ol"
+	}
+
+	var items []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if match := item.FindStringSubmatch(line); match != nil {
+			items = append(items, match[len(match)-1])
+			continue
+		}
+		if strings.TrimSpace(line) != "" && strings.HasPrefix(line, "  ") {
+			items[len(items)-1] += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		break
+	}
+
+	var output strings.Builder
+	output.WriteString("<" + tag + ">\n")
+	for _, content := range items {
+		output.WriteString("<li>" + renderInline(content, true) + "</li>\n")
+	}
+	output.WriteString("</" + tag + ">\n")
+	return output.String(), i - 1
+}
+
+func codeBlock(code, language string) string {
+	language = strings.ToLower(language)
+	if languageName.MatchString(language) {
+		return `<pre><code class="language-` + language + `">` + html.EscapeString(code) + "</code></pre>\n"
+	}
+	return "<pre><code>" + html.EscapeString(code) + "</code></pre>\n"
+}
+
+//renderInline renders code spans, links and emphasis. Code spans and link targets are
+//kept apart from the text so emphasis is never applied inside them
+func renderInline(text string, links bool) string {
+	var output, plain strings.Builder
+	flush := func() {
+		output.WriteString(emphasis(plain.String()))
+		plain.Reset()
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		if rest[0] == '`' {
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			if end := strings.Index(rest[ticks:], rest[:ticks]); end >= 0 {
+				flush()
+				code := strings.TrimSpace(rest[ticks : ticks+end])
+				output.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += ticks*2 + end
+				continue
+			}
+			plain.WriteString(rest[:ticks])
+			i += ticks
+			continue
+		}
+
+		if links && rest[0] == '[' {
+			if match := inlineLink.FindStringSubmatch(rest); match != nil {
+				flush()
+				output.WriteString(link(match[2], renderInline(match[1], false)))
+				i += len(match[0])
+				continue
+			}
+		}
+
+		if links && (rest[0] == 'h' || rest[0] == 'H') && (i == 0 || !isWordByte(text[i-1])) {
+			if match := bareLink.FindString(rest); match != "" {
+				match = strings.TrimRight(match, ".,;:!?'\")]")
+				flush()
+				output.WriteString(link(match, html.EscapeString(match)))
+				i += len(match)
+				continue
+			}
+		}
+
+		plain.WriteByte(rest[0])
+		i++
+	}
+	flush()
+	return output.String()
+}
+
+//emphasis renders the emphasis delimiters of escaped text. Triple stars are handled first so they nest properly
+func emphasis(text string) string {
+	text = html.EscapeString(text)
+	text = strongEm.ReplaceAllString(text, "<strong><em>$1</em></strong>")
+	text = strong.ReplaceAllString(text, "<strong>$1</strong>")
+	text = strikethrough.ReplaceAllString(text, "<del>$1</del>")
+	text = starEmphasis.ReplaceAllString(text, "<em>$1</em>")
+	text = lowEmphasis.ReplaceAllString(text, "$1<em>$2</em>$3")
+	return strings.ReplaceAll(text, "\n", "<br>\n")
+}
+
+//link renders an anchor when the target is safe, otherwise only its label
+func link(target, label string) string {
+	if !SafeURL(target) {
+		return label
+	}
+	return `<a href="` + html.EscapeString(target) + `">` + label + "</a>"
+}
+
+func isWordByte(character byte) bool {
+	return character == '_' || character == '/' ||
+		('a' <= character && character <= 'z') || ('A' <= character && character <= 'Z') || ('0' <= character && character <= '9')
+}
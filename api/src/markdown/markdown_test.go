@@ -0,0 +1,162 @@
+package markdown
+
+import "testing"
+
+func TestRenderEmphasis(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"**bold**", "<p><strong>bold</strong></p>\n"},
+		{"*em* and _em_", "<p><em>em</em> and <em>em</em></p>\n"},
+		{"***both***", "<p><strong><em>both</em></strong></p>\n"},
+		{"***both*** and **bold** and *em*", "<p><strong><em>both</em></strong> and <strong>bold</strong> and <em>em</em></p>\n"},
+		{"~~gone~~", "<p><del>gone</del></p>\n"},
+		{"snake_case_name", "<p>snake_case_name</p>\n"},
+		{"2 * 3 * 4", "<p>2 * 3 * 4</p>\n"},
+		{"`**code**`", "<p><code>**code**</code></p>\n"},
+	}
+	for _, test := range tests {
+		if got := Render(test.source); got != test.want {
+			t.Errorf("Render(%q) = %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"[click](javascript:alert)", "<p>click</p>\n"},
+		{"[site](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener noreferrer">site</a></p>` + "\n"},
+	}
+	for _, test := range tests {
+		if got := Render(test.source); got != test.want {
+			t.Errorf("Render(%q) = %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	const rel = ` rel="nofollow noopener noreferrer"`
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"[site](https://example.com/a?b=1&c=2)", `<p><a href="https://example.com/a?b=1&amp;c=2"` + rel + `>site</a></p>` + "\n"},
+		{"[mail](mailto:me@example.com)", `<p><a href="mailto:me@example.com"` + rel + `>mail</a></p>` + "\n"},
+		{"[click](javascript:alert)", "<p>click</p>\n"},
+		{"[click](JavaScript:alert)", "<p>click</p>\n"},
+		{"[click](data:text/html;base64,PHNjcmlwdD4=)", "<p>click</p>\n"},
+		{"[click](vbscript:msgbox)", "<p>click</p>\n"},
+		{"[click](//evil.example.com)", "<p>click</p>\n"},
+		{"[click](/relative)", "<p>click</p>\n"},
+		{`[click](https://example.com/"onmouseover="alert)`, `<p><a href="https://example.com/&#34;onmouseover=&#34;alert"` + rel + `>click</a></p>` + "\n"},
+		{"[**bold** label](https://example.com)", `<p><a href="https://example.com"` + rel + `><strong>bold</strong> label</a></p>` + "\n"},
+		{"see https://example.com/path.", `<p>see <a href="https://example.com/path"` + rel + `>https://example.com/path</a>.</p>` + "\n"},
+		{"javascript:alert(1)", "<p>javascript:alert(1)</p>\n"},
+		{"data:text/html,<script>alert(1)</script>", "<p>data:text/html,&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"xhttps://example.com", "<p>xhttps://example.com</p>\n"},
+		{"`https://example.com`", "<p><code>https://example.com</code></p>\n"},
+	}
+	for _, test := range tests {
+		if got := Render(test.source); got != test.want {
+			t.Errorf("Render(%q) = %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestRenderCodeBlocks(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"```go\nfunc main() {}\n```", `<pre><code class="language-go">func main() {}</code></pre>` + "\n"},
+		{"```C++\na < b\n```", `<pre><code class="language-c++">a &lt; b</code></pre>` + "\n"},
+		{"```\n<b>**not bold**</b>\n```", "<pre><code>&lt;b&gt;**not bold**&lt;/b&gt;</code></pre>\n"},
+		{"```\"onclick=\"alert(1)\nx\n```", "<pre><code>x</code></pre>\n"},
+		{"```go\"><script>alert(1)</script>\nx\n```", "<pre><code>x</code></pre>\n"},
+		{"~~~js\nlet a\n~~~", `<pre><code class="language-js">let a</code></pre>` + "\n"},
+		{"```go\nnever closed", `<pre><code class="language-go">never closed</code></pre>` + "\n"},
+	}
+	for _, test := range tests {
+		if got := Render(test.source); got != test.want {
+			t.Errorf("Render(%q) = %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestRenderListsAndQuotes(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"- one\n- *two*", "<ul>\n<li>one</li>\n<li><em>two</em></li>\n</ul>\n"},
+		{"1. one\n2) two\n  continued", "<ol>\n<li>one</li>\n<li>two<br>\ncontinued</li>\n</ol>\n"},
+		{"text\n- item", "<p>text</p>\n<ul>\n<li>item</li>\n</ul>\n"},
+		{"- <script>", "<ul>\n<li>&lt;script&gt;</li>\n</ul>\n"},
+		{"> quoted\n> **text**", "<blockquote>\n<p>quoted<br>\n<strong>text</strong></p>\n</blockquote>\n"},
+		{"> - item", "<blockquote>\n<ul>\n<li>item</li>\n</ul>\n</blockquote>\n"},
+		{"> > nested", "<blockquote>\n<blockquote>\n<p>nested</p>\n</blockquote>\n</blockquote>\n"},
+		{"> > > > too deep", "<blockquote>\n<blockquote>\n<blockquote>\n<p>&gt; too deep</p>\n</blockquote>\n</blockquote>\n</blockquote>\n"},
+	}
+	for _, test := range tests {
+		if got := Render(test.source); got != test.want {
+			t.Errorf("Render(%q) = %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	const rel = ` rel="nofollow noopener noreferrer"`
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`<a href="https://example.com">x</a>`, `<a href="https://example.com"` + rel + `>x</a>`},
+		{`<a href="javascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{`<a href="&#106;avascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{`<a href=" javascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{`<a href="data:text/html,x">x</a>`, `<a` + rel + `>x</a>`},
+		{`<a href="https://example.com" onclick="alert(1)" target="_top">x</a>`, `<a href="https://example.com"` + rel + `>x</a>`},
+		{`<code class="language-go">x</code>`, `<code class="language-go">x</code>`},
+		{`<code class="language-go evil">x</code>`, `<code>x</code>`},
+		{`<code class="x" style="color:red">x</code>`, `<code>x</code>`},
+		{`<p onmouseover="alert(1)">x</p>`, `<p>x</p>`},
+		{`<img src="x" onerror="alert(1)">`, `&lt;img src=&#34;x&#34; onerror=&#34;alert(1)&#34;&gt;`},
+		{`<script>alert(1)</script>`, `&lt;script&gt;alert(1)&lt;/script&gt;`},
+		{`<p>unterminated <a href="https://example.com"`, `<p>unterminated &lt;a href=&#34;https://example.com&#34;`},
+	}
+	for _, test := range tests {
+		if got := Sanitize(test.source); got != test.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		target string
+		safe   bool
+	}{
+		{"https://example.com", true},
+		{"HTTP://example.com", true},
+		{"mailto:me@example.com", true},
+		{"javascript:alert(1)", false},
+		{"JAVASCRIPT:alert(1)", false},
+		{"data:text/html,x", false},
+		{"vbscript:x", false},
+		{"file:///etc/passwd", false},
+		{"//example.com", false},
+		{"/relative", false},
+		{"https://", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if safe := SafeURL(test.target); safe != test.safe {
+			t.Errorf("SafeURL(%q) = %v, want %v", test.target, safe, test.safe)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// allowedTags are the only tags kept by Sanitize, every other tag is escaped
+var allowedTags = map[string]bool{
+	"p":          true,
+	"br":         true,
+	"strong":     true,
+	"em":         true,
+	"del":        true,
+	"code":       true,
+	"pre":        true,
+	"blockquote": true,
+	"ul":         true,
+	"ol":         true,
+	"li":         true,
+	"a":          true,
+}
+
+// allowedSchemes are the only link schemes kept by Sanitize
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// linkRel is added to every link so they can't be used to boost rankings or reach the opener window
+const linkRel = "nofollow noopener noreferrer"
+
+var (
+	tag       = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:\s+[a-zA-Z-]+="[^"<>]*")*)\s*/?>`)
+	attribute = regexp.MustCompile(`([a-zA-Z-]+)="([^"<>]*)"`)
+	codeClass = regexp.MustCompile(`^language-[a-z0-9_+#.-]{1,20}$`)
+)
+
+//Sanitize keeps only the allowed tags and attributes of the HTML and escapes everything else.
+//Links must have a http, https or mailto target and code may only have a language class
+func Sanitize(source string) string {
+	var output strings.Builder
+	last := 0
+	for _, match := range tag.FindAllStringSubmatchIndex(source, -1) {
+		output.WriteString(escapeText(source[last:match[0]]))
+		last = match[1]
+
+		closing := source[match[2]:match[3]] == "/"
+		name := strings.ToLower(source[match[4]:match[5]])
+		if !allowedTags[name] {
+			output.WriteString(escapeText(source[match[0]:match[1]]))
+			continue
+		}
+		if closing {
+			if name != "br" {
+				output.WriteString("</" + name + ">")
+			}
+			continue
+		}
+		output.WriteString("<" + name + allowedAttributes(name, source[match[6]:match[7]]) + ">")
+	}
+	output.WriteString(escapeText(source[last:]))
+	return output.String()
+}
+
+func allowedAttributes(name, attributes string) string {
+	var output strings.Builder
+	for _, match := range attribute.FindAllStringSubmatch(attributes, -1) {
+		key := strings.ToLower(match[1])
+		value := html.UnescapeString(match[2])
+		switch {
+		case name == "a" && key == "href" && SafeURL(value):
+			output.WriteString(` href="` + html.EscapeString(value) + `"`)
+		case name == "code" && key == "class" && codeClass.MatchString(value):
+			output.WriteString(` class="` + value + `"`)
+		}
+	}
+	if name == "a" {
+		output.WriteString(` rel="` + linkRel + `"`)
+	}
+	return output.String()
+}
+
+//escapeText escapes text that may already contain entities without escaping them twice
+func escapeText(text string) string {
+	return html.EscapeString(html.UnescapeString(text))
+}
+
+//SafeURL tells whether a link target has one of the allowed schemes
+func SafeURL(target string) bool {
+	link, error := url.Parse(target)
+	if error != nil {
+		return false
+	}
+	if !allowedSchemes[strings.ToLower(link.Scheme)] {
+		return false
+	}
+	return link.Scheme == "mailto" || link.Host != ""
+}
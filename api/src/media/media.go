@@ -0,0 +1,121 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	_ "image/gif" //Decoder
+	_ "image/png" //Decoder
+)
+
+// maxPixels protects the server from images that are small files but decode to huge bitmaps
+const maxPixels = 40000000
+
+const jpegQuality = 85
+
+// ErrTooLarge is returned for images with too many pixels
+var ErrTooLarge = errors.New("The image is too large")
+
+// Decodable tells which content types can be decoded, resized and thumbnailed
+var Decodable = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+//Decode reads a jpeg, png or gif image, refusing images with too many pixels
+func Decode(content []byte) (image.Image, error) {
+	imageConfig, _, error := image.DecodeConfig(bytes.NewReader(content))
+	if error != nil {
+		return nil, error
+	}
+	if imageConfig.Width*imageConfig.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	decodedImage, _, error := image.Decode(bytes.NewReader(content))
+	return decodedImage, error
+}
+
+//Fit scales the image down to fit in the box keeping its proportions, smaller images are not enlarged
+func Fit(source image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxWidth && height <= maxHeight {
+		return source
+	}
+	if width*maxHeight > height*maxWidth {
+		height = max(1, height*maxWidth/width)
+		width = maxWidth
+	} else {
+		width = max(1, width*maxHeight/height)
+		height = maxHeight
+	}
+	return scale(source, bounds, width, height)
+}
+
+//Fill scales and crops the center of the image so it covers exactly the size
+func Fill(source image.Image, width, height int) image.Image {
+	bounds := source.Bounds()
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+	if cropWidth*height > cropHeight*width {
+		cropWidth = max(1, cropHeight*width/height)
+	} else {
+		cropHeight = max(1, cropWidth*height/width)
+	}
+	x := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+	y := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+	return scale(source, image.Rect(x, y, x+cropWidth, y+cropHeight), width, height)
+}
+
+//EncodeJPEG encodes the image as a jpeg, transparent areas become white
+func EncodeJPEG(source image.Image) ([]byte, error) {
+	canvas := image.NewRGBA(source.Bounds())
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), source, source.Bounds().Min, draw.Over)
+
+	var buffer bytes.Buffer
+	if error := jpeg.Encode(&buffer, canvas, &jpeg.Options{Quality: jpegQuality}); error != nil {
+		return nil, error
+	}
+	return buffer.Bytes(), nil
+}
+
+//scale resizes the area of the source to the size averaging the pixels each destination pixel covers
+func scale(source image.Image, area image.Rectangle, width, height int) image.Image {
+	destination := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		top := area.Min.Y + y*area.Dy()/height
+		bottom := max(top+1, area.Min.Y+(y+1)*area.Dy()/height)
+		for x := 0; x < width; x++ {
+			left := area.Min.X + x*area.Dx()/width
+			right := max(left+1, area.Min.X+(x+1)*area.Dx()/width)
+
+			var red, green, blue, alpha, count uint64
+			for sourceY := top; sourceY < bottom; sourceY++ {
+				for sourceX := left; sourceX < right; sourceX++ {
+					r, g, b, a := source.At(sourceX, sourceY).RGBA()
+					red, green, blue, alpha = red+uint64(r), green+uint64(g), blue+uint64(b), alpha+uint64(a)
+					count++
+				}
+			}
+			destination.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(red / count),
+				G: uint16(green / count),
+				B: uint16(blue / count),
+				A: uint16(alpha / count),
+			})
+		}
+	}
+	return destination
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
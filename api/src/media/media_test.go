@@ -0,0 +1,115 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, source image.Image) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if error := png.Encode(&buffer, source); error != nil {
+		t.Fatalf("encoding the png: %v", error)
+	}
+	return buffer.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 30, 20))
+	decoded, error := Decode(encodePNG(t, source))
+	if error != nil {
+		t.Fatalf("Decode: %v", error)
+	}
+	if size := decoded.Bounds().Size(); size != image.Pt(30, 20) {
+		t.Errorf("decoded size is %v, want 30x20", size)
+	}
+
+	if _, error = Decode([]byte("not an image")); error == nil {
+		t.Errorf("Decode accepted a text file")
+	}
+}
+
+func TestDecodeRefusesTooManyPixels(t *testing.T) {
+	var buffer bytes.Buffer
+	if error := gif.Encode(&buffer, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black, color.White}), nil); error != nil {
+		t.Fatalf("encoding the gif: %v", error)
+	}
+	// The logical screen of a gif follows its 6 bytes signature, announcing 10000x10000 pixels
+	// makes a file of a few bytes that would decode to a 400MB bitmap
+	content := buffer.Bytes()
+	binary.LittleEndian.PutUint16(content[6:], 10000)
+	binary.LittleEndian.PutUint16(content[8:], 10000)
+
+	if _, error := Decode(content); error != ErrTooLarge {
+		t.Fatalf("Decode returned %v, want ErrTooLarge", error)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		width, height       int
+		maxWidth, maxHeight int
+		want                image.Point
+	}{
+		{800, 400, 400, 400, image.Pt(400, 200)},
+		{400, 800, 400, 400, image.Pt(200, 400)},
+		{1000, 1000, 400, 200, image.Pt(200, 200)},
+		{100, 50, 400, 400, image.Pt(100, 50)},
+		{4000, 1, 400, 400, image.Pt(400, 1)},
+	}
+	for _, test := range tests {
+		source := image.NewRGBA(image.Rect(0, 0, test.width, test.height))
+		if size := Fit(source, test.maxWidth, test.maxHeight).Bounds().Size(); size != test.want {
+			t.Errorf("Fit of %dx%d in %dx%d is %v, want %v", test.width, test.height, test.maxWidth, test.maxHeight, size, test.want)
+		}
+	}
+}
+
+func TestFillCropsTheCenter(t *testing.T) {
+	// A wide image, red on the sides and blue in the middle square
+	source := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			pixel := color.RGBA{R: 255, A: 255}
+			if x >= 100 && x < 200 {
+				pixel = color.RGBA{B: 255, A: 255}
+			}
+			source.SetRGBA(x, y, pixel)
+		}
+	}
+
+	filled := Fill(source, 50, 50)
+	if size := filled.Bounds().Size(); size != image.Pt(50, 50) {
+		t.Fatalf("Fill size is %v, want 50x50", size)
+	}
+	for _, point := range []image.Point{{0, 0}, {49, 49}, {25, 25}} {
+		if r, _, b, _ := filled.At(point.X, point.Y).RGBA(); r != 0 || b != 0xffff {
+			t.Errorf("pixel %v of the filled image is not blue, the center was not kept", point)
+		}
+	}
+}
+
+func TestEncodeJPEGThumbnail(t *testing.T) {
+	source := image.NewNRGBA(image.Rect(0, 0, 1200, 600))
+	content, error := EncodeJPEG(Fit(source, 400, 400))
+	if error != nil {
+		t.Fatalf("EncodeJPEG: %v", error)
+	}
+	thumbnail, error := jpeg.Decode(bytes.NewReader(content))
+	if error != nil {
+		t.Fatalf("the thumbnail is not a jpeg: %v", error)
+	}
+	if size := thumbnail.Bounds().Size(); size != image.Pt(400, 200) {
+		t.Errorf("thumbnail size is %v, want 400x200", size)
+	}
+	// The source is fully transparent, it must become white rather than black
+	if r, g, b, _ := thumbnail.At(200, 100).RGBA(); r < 0xf000 || g < 0xf000 || b < 0xf000 {
+		t.Errorf("transparent pixels became %d %d %d, want white", r>>8, g>>8, b>>8)
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Types of activity
+const (
+	// ActivityFollow is recorded when the actor starts following the user
+	ActivityFollow = "follow"
+	// ActivityLike is recorded when the actor likes the post
+	ActivityLike = "like"
+	// ActivityComment is recorded when the actor publishes a reply to another post
+	ActivityComment = "comment"
+	// ActivityPost is recorded when the actor publishes a post that is not a reply
+	ActivityPost = "post"
+)
+
+// Activity is something a user did, kept in an append-only log
+type Activity struct {
+	ID        uint64    `json:"id,omitempty"`
+	ActorID   uint64    `json:"actorID,omitempty"`
+	ActorNick string    `json:"actorNick,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	UserID    uint64    `json:"userID,omitempty"`
+	UserNick  string    `json:"userNick,omitempty"`
+	PostID    uint64    `json:"postID,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
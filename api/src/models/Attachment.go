@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// MaxAttachmentsPerPost is how many files a post can have
+const MaxAttachmentsPerPost = 4
+
+// AllowedAttachmentTypes maps the content types that can be uploaded to the extension of their files
+var AllowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+// Attachment represents a file uploaded to a post
+type Attachment struct {
+	ID           uint64    `json:"id,omitempty"`
+	PostID       uint64    `json:"postID,omitempty"`
+	ContentType  string    `json:"contentType,omitempty"`
+	Size         uint64    `json:"size,omitempty"`
+	Width        uint64    `json:"width,omitempty"`
+	Height       uint64    `json:"height,omitempty"`
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	URL          string    `json:"url,omitempty"`
+	ThumbnailURL string    `json:"thumbnailURL,omitempty"`
+	CreatedAt    time.Time `json:"createdAt,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxCollectionNameLength is how many characters the name of a bookmark collection can have
+const MaxCollectionNameLength = 50
+
+// Bookmark is a post the user saved for later, optionally in one of their collections
+type Bookmark struct {
+	CollectionID uint64 `json:"collectionID,omitempty"`
+}
+
+// Collection is a named group of bookmarks, only seen by its owner
+type Collection struct {
+	ID        uint64    `json:"id,omitempty"`
+	UserID    uint64    `json:"userID,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Bookmarks uint64    `json:"bookmarks"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+//Prepare trims and validates the name of the collection
+func (collection *Collection) Prepare() error {
+	collection.Name = strings.TrimSpace(collection.Name)
+	if collection.Name == "" {
+		return errors.New("Name can't be empty")
+	}
+	if utf8.RuneCountInString(collection.Name) > MaxCollectionNameLength {
+		return fmt.Errorf("Name can't be longer than %d characters", MaxCollectionNameLength)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package models
+
+// MaxLinksPerPost is how many links of a post get a preview
+const MaxLinksPerPost = 3
+
+// LinkPreview represents the OpenGraph summary of a page linked in a post
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//Limits of the lists
+const (
+	MaxListNameLength        = 50
+	MaxListDescriptionLength = 160
+	MaxListMembers           = 500
+)
+
+// List is a named group of users whose posts can be read as a timeline, private lists are only seen by their owner
+type List struct {
+	ID          uint64    `json:"id,omitempty"`
+	OwnerID     uint64    `json:"ownerID,omitempty"`
+	OwnerNick   string    `json:"ownerNick,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Private     bool      `json:"private"`
+	Members     uint64    `json:"members"`
+	CreatedAt   time.Time `json:"createdAt,omitempty"`
+}
+
+//Prepare trims and validates the name and the description of the list
+func (list *List) Prepare() error {
+	list.Name = strings.TrimSpace(list.Name)
+	list.Description = strings.TrimSpace(list.Description)
+	if list.Name == "" {
+		return errors.New("Name can't be empty")
+	}
+	if utf8.RuneCountInString(list.Name) > MaxListNameLength {
+		return fmt.Errorf("Name can't be longer than %d characters", MaxListNameLength)
+	}
+	if utf8.RuneCountInString(list.Description) > MaxListDescriptionLength {
+		return fmt.Errorf("Description can't be longer than %d characters", MaxListDescriptionLength)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaxMessageLength is the biggest message that can be sent
+const MaxMessageLength = 1000
+
+// Message represents a direct message between two users
+type Message struct {
+	ID             uint64    `json:"id,omitempty"`
+	ConversationID uint64    `json:"conversationID,omitempty"`
+	SenderID       uint64    `json:"senderID,omitempty"`
+	Content        string    `json:"content,omitempty"`
+	Read           bool      `json:"read"`
+	CreatedAt      time.Time `json:"createdAt,omitempty"`
+}
+
+// Conversation represents the direct messages between the user and another one
+type Conversation struct {
+	ID          uint64    `json:"id,omitempty"`
+	UserID      uint64    `json:"userID,omitempty"`
+	UserNick    string    `json:"userNick,omitempty"`
+	LastMessage *Message  `json:"lastMessage,omitempty"`
+	Unread      uint64    `json:"unread"`
+	UpdatedAt   time.Time `json:"updatedAt,omitempty"`
+}
+
+//Prepare validates and formats the message
+func (message *Message) Prepare() error {
+	message.Content = strings.TrimSpace(message.Content)
+	if message.Content == "" {
+		return errors.New("Content can't be empty")
+	}
+	if len([]rune(message.Content)) > MaxMessageLength {
+		return errors.New("Content can't have more than 1000 characters")
+	}
+	return nil
+}
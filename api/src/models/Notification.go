@@ -0,0 +1,56 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Types of notification
+const (
+	NotificationFollow         = "follow"
+	NotificationFollowRequest  = "follow_request"
+	NotificationFollowAccepted = "follow_accepted"
+	NotificationLike           = "like"
+	NotificationComment        = "comment"
+	NotificationMention        = "mention"
+	// NotificationReportResolved has no actor, so the moderator who handled the report stays anonymous
+	NotificationReportResolved = "report_resolved"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_]+)`)
+
+// Notification tells a user that someone interacted with them
+type Notification struct {
+	ID        uint64    `json:"id,omitempty"`
+	UserID    uint64    `json:"-"`
+	ActorID   uint64    `json:"actorID,omitempty"`
+	ActorNick string    `json:"actorNick,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	PostID    uint64    `json:"postID,omitempty"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+// NotificationPreferences represents which types of notification the user wants to receive
+type NotificationPreferences struct {
+	Follow  bool `json:"follow"`
+	Like    bool `json:"like"`
+	Comment bool `json:"comment"`
+	Mention bool `json:"mention"`
+}
+
+//ParseMentions returns the distinct nicks mentioned with @ in the content
+func ParseMentions(content string) []string {
+	var nicks []string
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		nick := strings.ToLower(match[1])
+		if seen[nick] {
+			continue
+		}
+		seen[nick] = true
+		nicks = append(nicks, nick)
+	}
+	return nicks
+}
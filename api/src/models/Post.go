@@ -6,17 +6,83 @@ import (
 	"time"
 )
 
+// Publication status of a post
+const (
+	// StatusPublished posts can be seen by the readers allowed by their visibility
+	StatusPublished = "published"
+	// StatusDraft posts are only seen by their author in the drafts
+	StatusDraft = "draft"
+	// StatusScheduled posts are published by the scheduler at their publication time
+	StatusScheduled = "scheduled"
+	// StatusHeld posts were held by the content policy until a moderator reviews them, they keep the status they
+	// had to go back to it when the hold is dismissed
+	StatusHeld = "held"
+)
+
+// MaxScheduleAhead is how far in the future a post can be scheduled
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+// MaxPinnedPosts is how many posts an author can pin to their profile
+const MaxPinnedPosts = 3
+
+// Visibility levels of a post
+const (
+	// VisibilityPublic posts can be seen by anyone and appear in tag feeds
+	VisibilityPublic = "public"
+	// VisibilityFollowers posts can only be seen by the followers of the author
+	VisibilityFollowers = "followers"
+	// VisibilityUnlisted posts can be seen by anyone with the link but are left out of tag feeds
+	VisibilityUnlisted = "unlisted"
+)
+
 // Post struct represents a publication
 type Post struct {
-	ID         uint64    `json:"id,omitempty"`
-	Title      string    `json:"title,omitempty"`
-	Content    string    `json:"content,omitempty"`
-	AuthorID   uint64    `json:"authorID,omitempty"`
-	AuthorNick string    `json:"authorNick,omitempty"`
-	Likes      uint64    `json:"likes"`
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
+	ID         uint64     `json:"id,omitempty"`
+	Title      string     `json:"title,omitempty"`
+	Content    string     `json:"content,omitempty"`
+	AuthorID   uint64     `json:"authorID,omitempty"`
+	AuthorNick string     `json:"authorNick,omitempty"`
+	Visibility string     `json:"visibility,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	PublishAt  *time.Time `json:"publishAt,omitempty"`
+	HeldStatus string     `json:"-"`
+	HeldReason string     `json:"-"`
+	QuoteOfID  uint64     `json:"quoteOfID,omitempty"`
+	ParentID   uint64     `json:"parentID,omitempty"`
+	Likes      uint64     `json:"likes"`
+	Reposts    uint64     `json:"reposts"`
+	Quotes     uint64     `json:"quotes"`
+	Replies    uint64     `json:"replies"`
+	Tags       []string   `json:"tags,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt,omitempty"`
+
+	ContentHTML    string        `json:"contentHTML,omitempty"`
+	RepostedByID   uint64        `json:"repostedByID,omitempty"`
+	RepostedByNick string        `json:"repostedByNick,omitempty"`
+	Attachments    []Attachment  `json:"attachments,omitempty"`
+	Links          []LinkPreview `json:"links,omitempty"`
+	BookmarkedByMe bool          `json:"bookmarkedByMe"`
+	Pinned         bool          `json:"pinned,omitempty"`
+	Children       []Post        `json:"children,omitempty"`
+}
+
+// Schedule is the time a post must be published at
+type Schedule struct {
+	PublishAt time.Time `json:"publishAt"`
+}
+
+// PinOrder is the order the pinned posts of an author are shown in
+type PinOrder struct {
+	PostIDs []uint64 `json:"postIDs"`
+}
+
+// Thread represents a post with the posts above it and a page of the replies below it
+type Thread struct {
+	Ancestors []Post `json:"ancestors"`
+	Post      Post   `json:"post"`
 }
 
+//Prepare validates and formats the post and parses its hashtags
 func (post *Post) Prepare() error {
 	if error := post.validate(); error != nil {
 		return error
@@ -33,13 +99,51 @@ func (post *Post) validate() error {
 	}
 
 	if post.Content == "" {
-		return errors.New("Title can't be empty")
+		return errors.New("Content can't be empty")
+	}
+
+	switch post.Visibility {
+	case "", VisibilityPublic, VisibilityFollowers, VisibilityUnlisted:
+	default:
+		return errors.New("Visibility must be public, followers or unlisted")
 	}
 
+	switch post.Status {
+	case "", StatusPublished, StatusDraft:
+		if post.PublishAt != nil {
+			return errors.New("Only scheduled posts can have a publication time")
+		}
+	case StatusScheduled:
+		if post.PublishAt == nil {
+			return errors.New("Scheduled posts need a publication time")
+		}
+		return ValidatePublishAt(*post.PublishAt)
+	default:
+		return errors.New("Status must be published, draft or scheduled")
+	}
+
+	return nil
+}
+
+//ValidatePublishAt checks that a post is scheduled in the future, but not too far
+func ValidatePublishAt(publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return errors.New("The publication time must be in the future")
+	}
+	if publishAt.After(time.Now().Add(MaxScheduleAhead)) {
+		return errors.New("Posts can't be scheduled more than a year ahead")
+	}
 	return nil
 }
 
 func (post *Post) format() {
 	post.Title = strings.TrimSpace(post.Title)
 	post.Content = strings.TrimSpace(post.Content)
+	post.Tags = ParseTags(post.Content)
+	if post.Visibility == "" {
+		post.Visibility = VisibilityPublic
+	}
+	if post.Status == "" {
+		post.Status = StatusPublished
+	}
 }
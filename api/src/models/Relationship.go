@@ -0,0 +1,18 @@
+package models
+
+// MaxRelationshipsPerRequest is how many users the relationships can be asked for at once
+const MaxRelationshipsPerRequest = 100
+
+// Relationship describes how the viewer and another user are connected
+type Relationship struct {
+	UserID      uint64 `json:"userID"`
+	Following   bool   `json:"following"`
+	FollowedBy  bool   `json:"followedBy"`
+	Blocked     bool   `json:"blocked"`
+	Muted       bool   `json:"muted"`
+	Requested   bool   `json:"requested"`
+	RequestedBy bool   `json:"requestedBy"`
+
+	// BlockedBy is not sent, users who blocked the viewer are reported as not found
+	BlockedBy bool `json:"-"`
+}
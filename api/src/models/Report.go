@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Reasons a post or a user can be reported for
+const (
+	ReasonSpam           = "spam"
+	ReasonHarassment     = "harassment"
+	ReasonHateSpeech     = "hate_speech"
+	ReasonViolence       = "violence"
+	ReasonNudity         = "nudity"
+	ReasonMisinformation = "misinformation"
+	ReasonOther          = "other"
+	// ReasonPolicy reports are created by the content policy for the posts it holds, users can't choose it
+	ReasonPolicy = "policy"
+)
+
+// Status of a report in the moderation queue
+const (
+	ReportOpen     = "open"
+	ReportClaimed  = "claimed"
+	ReportResolved = "resolved"
+)
+
+// Actions a moderator can take to resolve a report
+const (
+	ActionDismiss       = "dismiss"
+	ActionHidePost      = "hide_post"
+	ActionSuspendAuthor = "suspend_author"
+)
+
+// MaxReportDetailsLength is how many characters the reporter can add to explain the report
+const MaxReportDetailsLength = 500
+
+var reportReasons = map[string]bool{
+	ReasonSpam:           true,
+	ReasonHarassment:     true,
+	ReasonHateSpeech:     true,
+	ReasonViolence:       true,
+	ReasonNudity:         true,
+	ReasonMisinformation: true,
+	ReasonOther:          true,
+}
+
+// Report represents a post or a user flagged for the moderators
+type Report struct {
+	ID           uint64     `json:"id,omitempty"`
+	ReporterID   uint64     `json:"reporterID,omitempty"`
+	ReporterNick string     `json:"reporterNick,omitempty"`
+	UserID       uint64     `json:"userID,omitempty"`
+	UserNick     string     `json:"userNick,omitempty"`
+	PostID       uint64     `json:"postID,omitempty"`
+	PostContent  string     `json:"postContent,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	Details      string     `json:"details,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	ModeratorID  uint64     `json:"moderatorID,omitempty"`
+	Action       string     `json:"action,omitempty"`
+	ClaimedAt    *time.Time `json:"claimedAt,omitempty"`
+	ResolvedAt   *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt,omitempty"`
+}
+
+// Resolution is the action a moderator takes on a report
+type Resolution struct {
+	Action string `json:"action"`
+}
+
+//Prepare validates the reason and the details of the report
+func (report *Report) Prepare() error {
+	report.Details = strings.TrimSpace(report.Details)
+	if !reportReasons[report.Reason] {
+		return errors.New("Reason must be spam, harassment, hate_speech, violence, nudity, misinformation or other")
+	}
+	if utf8.RuneCountInString(report.Details) > MaxReportDetailsLength {
+		return fmt.Errorf("Details can't be longer than %d characters", MaxReportDetailsLength)
+	}
+	return nil
+}
+
+//Validate checks the action of the resolution
+func (resolution Resolution) Validate() error {
+	switch resolution.Action {
+	case ActionDismiss, ActionHidePost, ActionSuspendAuthor:
+		return nil
+	}
+	return errors.New("Action must be dismiss, hide_post or suspend_author")
+}
@@ -0,0 +1,7 @@
+package models
+
+// Settings represents the privacy settings of the user
+type Settings struct {
+	AllowDMs bool `json:"allowDMs"`
+	Private  bool `json:"private"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	// MaxStatsDays is how many days of stats can be asked for at once
+	MaxStatsDays = 365
+	// DayLayout formats the days of the stats
+	DayLayout = "2006-01-02"
+)
+
+// DailyStats are the numbers of a post or an author in a day
+type DailyStats struct {
+	Day          string `json:"day,omitempty"`
+	Impressions  uint64 `json:"impressions"`
+	Likes        uint64 `json:"likes"`
+	NewFollowers uint64 `json:"newFollowers"`
+}
+
+// Stats is a time series of daily stats, the oldest day first, with the totals of the period
+type Stats struct {
+	Days  []DailyStats `json:"days"`
+	Total DailyStats   `json:"total"`
+}
+
+//NewStats lays out the days from the first to the last, with the stats of the days that have some
+func NewStats(first, last time.Time, days []DailyStats) Stats {
+	byDay := make(map[string]DailyStats, len(days))
+	for _, day := range days {
+		byDay[day.Day] = day
+	}
+
+	stats := Stats{Days: []DailyStats{}}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		name := day.Format(DayLayout)
+		dailyStats, found := byDay[name]
+		if !found {
+			dailyStats = DailyStats{Day: name}
+		}
+		stats.Days = append(stats.Days, dailyStats)
+		stats.Total.Impressions += dailyStats.Impressions
+		stats.Total.Likes += dailyStats.Likes
+		stats.Total.NewFollowers += dailyStats.NewFollowers
+	}
+	return stats
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxTagLength is the biggest tag that can be stored
+const MaxTagLength = 50
+
+var tagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#/])#([\p{L}\p{N}_]+)`)
+var validTag = regexp.MustCompile(`^[\p{L}\p{N}_]+$`)
+
+// Tag represents a hashtag and how many posts used it
+type Tag struct {
+	Name  string `json:"name"`
+	Posts uint64 `json:"posts,omitempty"`
+}
+
+//ParseTags returns the distinct hashtags found in the content, in lower case and without the #
+func ParseTags(content string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, match := range tagPattern.FindAllStringSubmatch(content, -1) {
+		tag := strings.ToLower(match[1])
+		if len([]rune(tag)) > MaxTagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+//NormalizeTag formats a tag received in a request and reports whether it is valid
+func NormalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if !validTag.MatchString(tag) || len([]rune(tag)) > MaxTagLength {
+		return "", false
+	}
+	return tag, true
+}
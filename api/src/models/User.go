@@ -3,8 +3,11 @@ package models
 import (
 	"api/src/security"
 	"errors"
+	"fmt"
+	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/badoux/checkmail"
 )
@@ -16,9 +19,38 @@ type User struct {
 	Nick      string    `json:"nick,omitempty"`
 	Email     string    `json:"email,omitempty"`
 	Password  string    `json:"password,omitempty"`
+	Bio       string    `json:"bio,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Website   string    `json:"website,omitempty"`
 	CreatedAt time.Time `json:"createdAt,omitempty"`
+
+	AvatarKey string            `json:"-"`
+	BannerKey string            `json:"-"`
+	Avatar    map[string]string `json:"avatar,omitempty"`
+	Banner    string            `json:"banner,omitempty"`
+}
+
+//Profile is a user with the counts shown on their profile
+type Profile struct {
+	User
+	PostCount      uint64 `json:"postCount"`
+	FollowerCount  uint64 `json:"followerCount"`
+	FollowingCount uint64 `json:"followingCount"`
+}
+
+//Suggestion is a user suggested to follow and how many of the users followed by the viewer follow them
+type Suggestion struct {
+	User
+	MutualFollows uint64 `json:"mutualFollows"`
 }
 
+//Limits of the profile fields, in characters
+const (
+	MaxBioLength      = 160
+	MaxLocationLength = 50
+	MaxWebsiteLength  = 100
+)
+
 //Prepare calls methods to format the user
 func (user *User) Prepare(step string) error {
 	if error := user.validate(step); error != nil {
@@ -50,6 +82,30 @@ func (user *User) validate(step string) error {
 	if step == "register" && user.Password == "" {
 		return errors.New("Password can't be empty ")
 	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(user.Bio)) > MaxBioLength {
+		return fmt.Errorf("Bio can't be longer than %d characters", MaxBioLength)
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(user.Location)) > MaxLocationLength {
+		return fmt.Errorf("Location can't be longer than %d characters", MaxLocationLength)
+	}
+
+	return validateWebsite(strings.TrimSpace(user.Website))
+}
+
+//validateWebsite only accepts absolute http and https links, so they are safe to render as links
+func validateWebsite(website string) error {
+	if website == "" {
+		return nil
+	}
+	if len(website) > MaxWebsiteLength {
+		return fmt.Errorf("Website can't be longer than %d characters", MaxWebsiteLength)
+	}
+	link, error := url.Parse(website)
+	if error != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return errors.New("Website must be a http or https link")
+	}
 	return nil
 }
 
@@ -57,6 +113,9 @@ func (user *User) format(step string) error {
 	user.Name = strings.TrimSpace(user.Name)
 	user.Nick = strings.TrimSpace(user.Nick)
 	user.Email = strings.TrimSpace(user.Email)
+	user.Bio = strings.TrimSpace(user.Bio)
+	user.Location = strings.TrimSpace(user.Location)
+	user.Website = strings.TrimSpace(user.Website)
 	if step == "register" {
 		passwordWithHash, error := security.Hash(user.Password)
 		if error != nil {
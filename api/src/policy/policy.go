@@ -0,0 +1,82 @@
+package policy
+
+import (
+	"api/src/config"
+	"time"
+)
+
+// Outcomes of the content policy, from the mildest to the most severe
+const (
+	Allow  = "allow"
+	Hold   = "hold"
+	Reject = "reject"
+)
+
+var severity = map[string]int{Allow: 0, Hold: 1, Reject: 2}
+
+// Decision is the outcome of the policy for a post and the reason for it
+type Decision struct {
+	Outcome string
+	Reason  string
+}
+
+// Submission is a post being created or edited
+type Submission struct {
+	PostID   uint64
+	AuthorID uint64
+	Title    string
+	Content  string
+}
+
+// History gives the rules access to what the author posted before
+type History interface {
+	RecentContents(authorID, exceptPostID uint64, since time.Time) ([]string, error)
+	CountSince(authorID uint64, since time.Time) (uint64, error)
+}
+
+// Rule checks one aspect of a post
+type Rule interface {
+	Check(submission Submission, history History) (Decision, error)
+}
+
+// Pipeline runs the rules of the content policy
+type Pipeline struct {
+	rules []Rule
+}
+
+// Default is the content policy of the API
+var Default = NewPipeline()
+
+//NewPipeline creates a pipeline with the rules
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules}
+}
+
+//Load creates the default pipeline with the rules and limits of the configuration
+func Load() {
+	Default = NewPipeline(
+		NewWordList(config.BannedWords, Reject),
+		NewWordList(config.ReviewWords, Hold),
+		LinkSpam{MaxLinks: config.PolicyMaxLinks},
+		Duplicates{Window: config.DuplicateWindow},
+		RateLimit{MaxPosts: config.MaxPostsPerHour, Period: time.Hour},
+	)
+}
+
+//Check runs the rules in order and returns the most severe decision, stopping at the first rejection
+func (pipeline *Pipeline) Check(submission Submission, history History) (Decision, error) {
+	decision := Decision{Outcome: Allow}
+	for _, rule := range pipeline.rules {
+		ruleDecision, error := rule.Check(submission, history)
+		if error != nil {
+			return Decision{}, error
+		}
+		if severity[ruleDecision.Outcome] > severity[decision.Outcome] {
+			decision = ruleDecision
+		}
+		if decision.Outcome == Reject {
+			break
+		}
+	}
+	return decision, nil
+}
@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// minTextAroundLinks is how many letters a post with several links needs besides them not to look like spam
+const minTextAroundLinks = 20
+
+var (
+	word = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	link = regexp.MustCompile(`(?i)\bhttps?://\S+`)
+)
+
+// WordList decides the outcome of posts containing any of its words or phrases.
+// Words are matched whole and case insensitively, so "class" doesn't match "ass"
+type WordList struct {
+	phrases []string
+	outcome string
+}
+
+//NewWordList creates a word list with the outcome for the posts that contain its words
+func NewWordList(words []string, outcome string) WordList {
+	list := WordList{outcome: outcome}
+	for _, phrase := range words {
+		if normalized := normalizeWords(phrase); normalized != "  " {
+			list.phrases = append(list.phrases, normalized)
+		}
+	}
+	return list
+}
+
+//Check looks for the words of the list in the title and content of the post
+func (list WordList) Check(submission Submission, _ History) (Decision, error) {
+	text := normalizeWords(submission.Title + "\n" + submission.Content)
+	for _, phrase := range list.phrases {
+		if strings.Contains(text, phrase) {
+			return Decision{Outcome: list.outcome, Reason: "The post contains words that are not allowed"}, nil
+		}
+	}
+	return Decision{Outcome: Allow}, nil
+}
+
+// LinkSpam rejects posts with too many links and holds posts with several links and hardly any text
+type LinkSpam struct {
+	MaxLinks int
+}
+
+//Check counts the links of the post
+func (rule LinkSpam) Check(submission Submission, _ History) (Decision, error) {
+	links := link.FindAllString(submission.Content, -1)
+	if len(links) > rule.MaxLinks {
+		return Decision{Outcome: Reject, Reason: fmt.Sprintf("Posts can't have more than %d links", rule.MaxLinks)}, nil
+	}
+	text := link.ReplaceAllString(submission.Content, "")
+	if len(links) >= 2 && len(strings.Join(word.FindAllString(text, -1), "")) < minTextAroundLinks {
+		return Decision{Outcome: Hold, Reason: "The post is mostly links"}, nil
+	}
+	return Decision{Outcome: Allow}, nil
+}
+
+// Duplicates rejects posts with the same content as another post of the author within the window
+type Duplicates struct {
+	Window time.Duration
+}
+
+//Check compares the post with the recent posts of its author, ignoring case and spacing
+func (rule Duplicates) Check(submission Submission, history History) (Decision, error) {
+	if rule.Window <= 0 {
+		return Decision{Outcome: Allow}, nil
+	}
+	contents, error := history.RecentContents(submission.AuthorID, submission.PostID, time.Now().Add(-rule.Window))
+	if error != nil {
+		return Decision{}, error
+	}
+	content := normalizeWords(submission.Content)
+	if content == "  " {
+		return Decision{Outcome: Allow}, nil
+	}
+	for _, recentContent := range contents {
+		if normalizeWords(recentContent) == content {
+			return Decision{Outcome: Reject, Reason: "You already posted this"}, nil
+		}
+	}
+	return Decision{Outcome: Allow}, nil
+}
+
+// RateLimit rejects new posts from authors who posted too much within the period
+type RateLimit struct {
+	MaxPosts int
+	Period   time.Duration
+}
+
+//Check counts the recent posts of the author, edits are not limited
+func (rule RateLimit) Check(submission Submission, history History) (Decision, error) {
+	if submission.PostID != 0 {
+		return Decision{Outcome: Allow}, nil
+	}
+	count, error := history.CountSince(submission.AuthorID, time.Now().Add(-rule.Period))
+	if error != nil {
+		return Decision{}, error
+	}
+	if count >= uint64(rule.MaxPosts) {
+		return Decision{Outcome: Reject, Reason: "You are posting too fast, try again later"}, nil
+	}
+	return Decision{Outcome: Allow}, nil
+}
+
+//normalizeWords lowercases the words of the text and separates them by single spaces, with a space at each end
+//so whole words and phrases can be found with strings.Contains
+func normalizeWords(text string) string {
+	return " " + strings.Join(word.FindAllString(strings.ToLower(text), -1), " ") + " "
+}
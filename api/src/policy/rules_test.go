@@ -0,0 +1,198 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeHistory is a History of one author, their posts are stored by ID with their creation time
+type fakeHistory struct {
+	contents map[uint64]string
+	times    map[uint64]time.Time
+	error    error
+}
+
+func newFakeHistory() *fakeHistory {
+	return &fakeHistory{contents: map[uint64]string{}, times: map[uint64]time.Time{}}
+}
+
+func (history *fakeHistory) add(postID uint64, content string, age time.Duration) {
+	history.contents[postID] = content
+	history.times[postID] = time.Now().Add(-age)
+}
+
+func (history *fakeHistory) RecentContents(_, exceptPostID uint64, since time.Time) ([]string, error) {
+	var contents []string
+	for postID, content := range history.contents {
+		if postID != exceptPostID && !history.times[postID].Before(since) {
+			contents = append(contents, content)
+		}
+	}
+	return contents, history.error
+}
+
+func (history *fakeHistory) CountSince(_ uint64, since time.Time) (uint64, error) {
+	var count uint64
+	for _, createdAt := range history.times {
+		if !createdAt.Before(since) {
+			count++
+		}
+	}
+	return count, history.error
+}
+
+func TestWordList(t *testing.T) {
+	list := NewWordList([]string{"ass", "Bad Word", "  ", "spam!"}, Hold)
+	tests := []struct {
+		title, content string
+		outcome        string
+	}{
+		{"", "A first class post", Allow},
+		{"", "You ass", Hold},
+		{"", "ASS.", Hold},
+		{"Ass", "in the title", Hold},
+		{"", "a bad word here", Hold},
+		{"", "a BAD\n\tword here", Hold},
+		{"", "a bad-word here", Hold},
+		{"", "a badword here", Allow},
+		{"", "bad words", Allow},
+		{"", "buy spam now", Hold},
+		{"", "assassin", Allow},
+		{"", "", Allow},
+	}
+	for _, test := range tests {
+		decision, error := list.Check(Submission{Title: test.title, Content: test.content}, newFakeHistory())
+		if error != nil {
+			t.Fatalf("Check: %v", error)
+		}
+		if decision.Outcome != test.outcome {
+			t.Errorf("WordList on %q %q is %s, want %s", test.title, test.content, decision.Outcome, test.outcome)
+		}
+	}
+}
+
+func TestLinkSpam(t *testing.T) {
+	rule := LinkSpam{MaxLinks: 3}
+	text := "Some words to go around the links here"
+	tests := []struct {
+		name    string
+		content string
+		outcome string
+	}{
+		{"no link", text, Allow},
+		{"one bare link", "https://a.example.com", Allow},
+		{"two links with text", text + " https://a.example.com http://b.example.com", Allow},
+		{"two bare links", "https://a.example.com http://b.example.com", Hold},
+		{"two links with little text", "look https://a.example.com and https://b.example.com", Hold},
+		{"as many links as allowed", text + strings.Repeat(" https://a.example.com", 3), Allow},
+		{"too many links", text + strings.Repeat(" https://a.example.com", 4), Reject},
+		{"upper case scheme", strings.Repeat(" HTTPS://A.EXAMPLE.COM", 4), Reject},
+	}
+	for _, test := range tests {
+		decision, error := rule.Check(Submission{Content: test.content}, newFakeHistory())
+		if error != nil {
+			t.Fatalf("Check: %v", error)
+		}
+		if decision.Outcome != test.outcome {
+			t.Errorf("%s: LinkSpam is %s, want %s", test.name, decision.Outcome, test.outcome)
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	history := newFakeHistory()
+	history.add(1, "Hello,   World!", time.Minute)
+	history.add(2, "An old post", 2*time.Hour)
+	rule := Duplicates{Window: time.Hour}
+
+	tests := []struct {
+		name     string
+		postID   uint64
+		content  string
+		disabled bool
+		outcome  string
+	}{
+		{"same content", 0, "Hello, World!", false, Reject},
+		{"different case and spacing", 0, "hello world", false, Reject},
+		{"different content", 0, "Hello there", false, Allow},
+		{"outside the window", 0, "An old post", false, Allow},
+		{"editing the same post", 1, "Hello, World!", false, Allow},
+		{"only punctuation", 0, "!!!", false, Allow},
+		{"disabled", 0, "Hello, World!", true, Allow},
+	}
+	for _, test := range tests {
+		check := rule
+		if test.disabled {
+			check = Duplicates{}
+		}
+		decision, error := check.Check(Submission{PostID: test.postID, Content: test.content}, history)
+		if error != nil {
+			t.Fatalf("Check: %v", error)
+		}
+		if decision.Outcome != test.outcome {
+			t.Errorf("%s: Duplicates is %s, want %s", test.name, decision.Outcome, test.outcome)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	rule := RateLimit{MaxPosts: 2, Period: time.Hour}
+	tests := []struct {
+		name    string
+		ages    []time.Duration
+		postID  uint64
+		outcome string
+	}{
+		{"no post", nil, 0, Allow},
+		{"below the limit", []time.Duration{time.Minute}, 0, Allow},
+		{"at the limit", []time.Duration{time.Minute, 30 * time.Minute}, 0, Reject},
+		{"older posts don't count", []time.Duration{time.Minute, 2 * time.Hour, 3 * time.Hour}, 0, Allow},
+		{"edits are not limited", []time.Duration{time.Minute, 30 * time.Minute}, 5, Allow},
+	}
+	for _, test := range tests {
+		history := newFakeHistory()
+		for i, age := range test.ages {
+			history.add(uint64(i+1), "post", age)
+		}
+		decision, error := rule.Check(Submission{PostID: test.postID, Content: "new"}, history)
+		if error != nil {
+			t.Fatalf("Check: %v", error)
+		}
+		if decision.Outcome != test.outcome {
+			t.Errorf("%s: RateLimit is %s, want %s", test.name, decision.Outcome, test.outcome)
+		}
+	}
+}
+
+func TestPipelineKeepsTheMostSevereDecision(t *testing.T) {
+	history := newFakeHistory()
+	pipeline := NewPipeline(
+		NewWordList([]string{"review"}, Hold),
+		NewWordList([]string{"banned"}, Reject),
+	)
+	tests := []struct {
+		content string
+		outcome string
+	}{
+		{"fine", Allow},
+		{"please review", Hold},
+		{"banned", Reject},
+		{"review banned", Reject},
+	}
+	for _, test := range tests {
+		decision, error := pipeline.Check(Submission{Content: test.content}, history)
+		if error != nil {
+			t.Fatalf("Check: %v", error)
+		}
+		if decision.Outcome != test.outcome {
+			t.Errorf("pipeline on %q is %s, want %s", test.content, decision.Outcome, test.outcome)
+		}
+	}
+
+	history.error = errors.New("database is down")
+	if _, error := NewPipeline(RateLimit{MaxPosts: 1, Period: time.Hour}).Check(Submission{}, history); error == nil {
+		t.Errorf("the error of the history was lost")
+	}
+}
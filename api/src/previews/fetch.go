@@ -0,0 +1,157 @@
+package previews
+
+import (
+	"api/src/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	// fetchTimeout bounds the whole request, redirects and body included
+	fetchTimeout = 5 * time.Second
+	dialTimeout  = 2 * time.Second
+	// maxBodySize is how much of the page is read, the OpenGraph tags are at its head
+	maxBodySize  = 512 << 10
+	maxRedirects = 3
+	userAgent    = "DevbookBot/1.0 (link preview)"
+)
+
+// ErrForbiddenAddress is returned for links that lead to private networks
+var ErrForbiddenAddress = errors.New("The link leads to a forbidden address")
+
+// Fetcher downloads pages to build their previews
+type Fetcher struct {
+	client *http.Client
+}
+
+//NewFetcher creates a fetcher with strict timeouts that refuses to connect to loopback, private,
+//link local and other internal addresses, even through redirects or DNS names resolving to them.
+//allowPrivate lifts this protection and must only be used against local stand-ins
+func NewFetcher(allowPrivate bool) *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivate {
+				return nil
+			}
+			host, _, error := net.SplitHostPort(address)
+			if error != nil {
+				return error
+			}
+			if ip := net.ParseIP(host); ip == nil || forbiddenIP(ip) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   dialTimeout,
+		ResponseHeaderTimeout: fetchTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   fetchTimeout,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return errors.New("Too many redirects")
+			}
+			if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+	return &Fetcher{client}
+}
+
+//Fetch downloads the page and extracts its preview
+func (fetcher *Fetcher) Fetch(ctx context.Context, link string) (models.LinkPreview, error) {
+	page, error := url.Parse(link)
+	if error != nil {
+		return models.LinkPreview{}, error
+	}
+	if (page.Scheme != "http" && page.Scheme != "https") || page.Host == "" {
+		return models.LinkPreview{}, ErrForbiddenAddress
+	}
+
+	request, error := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if error != nil {
+		return models.LinkPreview{}, error
+	}
+	request.Header.Set("User-Agent", userAgent)
+	request.Header.Set("Accept", "text/html")
+
+	response, error := fetcher.client.Do(request)
+	if error != nil {
+		return models.LinkPreview{}, error
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return models.LinkPreview{}, fmt.Errorf("The page answered with status %d", response.StatusCode)
+	}
+	contentType, _, error := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if error != nil || (contentType != "text/html" && contentType != "application/xhtml+xml") {
+		return models.LinkPreview{}, fmt.Errorf("The page is not HTML")
+	}
+
+	body, error := ioutil.ReadAll(io.LimitReader(response.Body, maxBodySize))
+	if error != nil {
+		return models.LinkPreview{}, error
+	}
+
+	preview := parse(string(body), response.Request.URL)
+	preview.URL = link
+	if preview.Title == "" && preview.Description == "" {
+		return models.LinkPreview{}, errors.New("The page has nothing to preview")
+	}
+	return preview, nil
+}
+
+// forbiddenNetworks are the ranges not covered by the net.IP methods that must not be reached
+var forbiddenNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+func forbiddenIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, error := net.ParseCIDR(cidr)
+		if error != nil {
+			panic(error)
+		}
+		networks[i] = network
+	}
+	return networks
+}
@@ -0,0 +1,181 @@
+package previews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("User-Agent is %q", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>Local page</title><meta name="description" content="A page">
+			<meta property="og:image" content="/cover.png"></head></html>`)
+	}))
+	defer server.Close()
+
+	preview, error := NewFetcher(true).Fetch(context.Background(), server.URL+"/page")
+	if error != nil {
+		t.Fatalf("Fetch: %v", error)
+	}
+	if preview.URL != server.URL+"/page" || preview.Title != "Local page" || preview.Description != "A page" ||
+		preview.Image != server.URL+"/cover.png" {
+		t.Errorf("preview is %+v", preview)
+	}
+}
+
+func TestFetchRefusesNonHTML(t *testing.T) {
+	tests := map[string]http.HandlerFunc{
+		"not found": func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		},
+		"image": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			fmt.Fprint(w, `<title>Not really</title>`)
+		},
+		"empty page": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body>Hello</body></html>`)
+		},
+	}
+	for name, handler := range tests {
+		server := httptest.NewServer(handler)
+		if _, error := NewFetcher(true).Fetch(context.Background(), server.URL); error == nil {
+			t.Errorf("%s: Fetch succeeded", name)
+		}
+		server.Close()
+	}
+}
+
+func TestFetchReadsAtMostMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<meta name="description" content="Early">`)
+		fmt.Fprint(w, strings.Repeat(" ", maxBodySize))
+		fmt.Fprint(w, `<title>Late</title>`)
+	}))
+	defer server.Close()
+
+	preview, error := NewFetcher(true).Fetch(context.Background(), server.URL)
+	if error != nil {
+		t.Fatalf("Fetch: %v", error)
+	}
+	if preview.Description != "Early" || preview.Title != "" {
+		t.Errorf("preview is %q, %q, the title after %d bytes must not be read", preview.Title, preview.Description, maxBodySize)
+	}
+}
+
+func TestFetchFollowsAtMostMaxRedirects(t *testing.T) {
+	// /hops/n redirects n more times before serving the page
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hops, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hops/"))
+		if hops > 0 {
+			http.Redirect(w, r, "/hops/"+strconv.Itoa(hops-1), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<title>Arrived</title>`)
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(true)
+	if preview, error := fetcher.Fetch(context.Background(), server.URL+"/hops/"+strconv.Itoa(maxRedirects)); error != nil || preview.Title != "Arrived" {
+		t.Errorf("Fetch with %d redirects returned %+v, %v", maxRedirects, preview, error)
+	}
+	if _, error := fetcher.Fetch(context.Background(), server.URL+"/hops/"+strconv.Itoa(maxRedirects+1)); error == nil {
+		t.Errorf("Fetch with %d redirects succeeded", maxRedirects+1)
+	}
+}
+
+func TestFetchRefusesRedirectsToOtherSchemes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, error := NewFetcher(true).Fetch(context.Background(), server.URL); !errors.Is(error, ErrForbiddenAddress) {
+		t.Errorf("Fetch returned %v, want ErrForbiddenAddress", error)
+	}
+}
+
+func TestFetchRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("the fetcher reached the local server at %s", r.URL)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// The dialer refuses the address before connecting, so the unreachable ones fail the same way
+	links := []string{
+		server.URL,
+		"http://localhost:" + port,
+		"http://127.0.0.1:" + port,
+		"http://10.0.0.1/",
+		"http://10.255.255.254:8080/",
+		"http://[::1]:" + port,
+		"http://[::ffff:127.0.0.1]:" + port,
+		"ftp://example.com/",
+		"/relative",
+	}
+	fetcher := NewFetcher(false)
+	for _, link := range links {
+		if _, error := fetcher.Fetch(context.Background(), link); !errors.Is(error, ErrForbiddenAddress) {
+			t.Errorf("Fetch(%s) returned %v, want ErrForbiddenAddress", link, error)
+		}
+	}
+}
+
+func TestFetchRefusesRedirectsToPrivateAddresses(t *testing.T) {
+	// The redirect target can't be a real public server in tests, so the fetcher is allowed to reach the
+	// first server only: its dialer refuses any other address
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://10.0.0.1/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, error := NewFetcher(false).Fetch(context.Background(), server.URL); !errors.Is(error, ErrForbiddenAddress) {
+		t.Errorf("Fetch returned %v, want ErrForbiddenAddress", error)
+	}
+}
+
+func TestForbiddenIP(t *testing.T) {
+	tests := []struct {
+		address   string
+		forbidden bool
+	}{
+		{"127.0.0.1", true},
+		{"127.1.2.3", true},
+		{"10.0.0.1", true},
+		{"10.255.255.255", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"::1", true},
+		{"::", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"fc00::1", true},
+		{"fe80::1", true},
+		{"64:ff9b::a00:1", true},
+		{"93.184.216.34", false},
+		{"11.0.0.1", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+	}
+	for _, test := range tests {
+		if forbidden := forbiddenIP(net.ParseIP(test.address)); forbidden != test.forbidden {
+			t.Errorf("forbiddenIP(%s) = %v, want %v", test.address, forbidden, test.forbidden)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package previews
+
+import (
+	"api/src/models"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lengths of the preview fields, as in the link_previews table
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 500
+	maxURLLength         = 2048
+)
+
+var (
+	metaTag      = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	titleTag     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	tagAttribute = regexp.MustCompile(`(?s)([a-zA-Z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	whitespace   = regexp.MustCompile(`\s+`)
+	linkInText   = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+)
+
+//ExtractURLs finds the distinct http and https links of a text, up to the limit of links per post
+func ExtractURLs(text string) []string {
+	var links []string
+	seen := map[string]bool{}
+	for _, link := range linkInText.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:!?")
+		if seen[link] || len(link) > maxURLLength {
+			continue
+		}
+		if parsed, error := url.Parse(link); error != nil || parsed.Host == "" {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+		if len(links) == models.MaxLinksPerPost {
+			break
+		}
+	}
+	return links
+}
+
+//parse extracts the OpenGraph title, description and image of the page, falling back
+//to its title tag and description meta tag
+func parse(page string, base *url.URL) models.LinkPreview {
+	properties := map[string]string{}
+	for _, tag := range metaTag.FindAllString(page, -1) {
+		attributes := map[string]string{}
+		for _, match := range tagAttribute.FindAllStringSubmatch(tag, -1) {
+			attributes[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+		}
+		key := attributes["property"]
+		if key == "" {
+			key = attributes["name"]
+		}
+		key = strings.ToLower(key)
+		if _, found := properties[key]; key != "" && !found {
+			properties[key] = attributes["content"]
+		}
+	}
+
+	preview := models.LinkPreview{
+		Title:       clean(properties["og:title"], maxTitleLength),
+		Description: clean(properties["og:description"], maxDescriptionLength),
+		Image:       resolve(base, clean(properties["og:image"], maxURLLength)),
+	}
+	if preview.Title == "" {
+		if match := titleTag.FindStringSubmatch(page); match != nil {
+			preview.Title = clean(match[1], maxTitleLength)
+		}
+	}
+	if preview.Description == "" {
+		preview.Description = clean(properties["description"], maxDescriptionLength)
+	}
+	return preview
+}
+
+//clean decodes the entities, collapses the whitespace and cuts the text to the given number of characters
+func clean(text string, maxLength int) string {
+	text = strings.TrimSpace(whitespace.ReplaceAllString(html.UnescapeString(text), " "))
+	if utf8.RuneCountInString(text) > maxLength {
+		text = string([]rune(text)[:maxLength])
+	}
+	return text
+}
+
+//resolve turns a relative image address into an absolute one, only http and https images are kept
+func resolve(base *url.URL, image string) string {
+	if image == "" {
+		return ""
+	}
+	reference, error := url.Parse(image)
+	if error != nil {
+		return ""
+	}
+	absolute := base.ResolveReference(reference)
+	if absolute.Scheme != "http" && absolute.Scheme != "https" {
+		return ""
+	}
+	if link := absolute.String(); len(link) <= maxURLLength {
+		return link
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package previews
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/articles/1")
+	tests := []struct {
+		name                      string
+		page                      string
+		title, description, image string
+	}{
+		{
+			name: "OpenGraph",
+			page: `<html><head><title>Page title</title>
+				<meta property="og:title" content="OG &amp; title">
+				<meta content='OG description' property='og:description'>
+				<meta property="og:image" content="https://cdn.example.com/a.png">
+				<meta name="description" content="Meta description"></head></html>`,
+			title: "OG & title", description: "OG description", image: "https://cdn.example.com/a.png",
+		},
+		{
+			name: "fallback to the title and description tags",
+			page: `<HTML><HEAD><TITLE>
+				Page
+				title </TITLE><META NAME="Description" CONTENT="Meta description"></HEAD></HTML>`,
+			title: "Page title", description: "Meta description",
+		},
+		{
+			name:  "first tag wins",
+			page:  `<meta property="og:title" content="First"><meta property="og:title" content="Second">`,
+			title: "First",
+		},
+		{
+			name:  "relative image",
+			page:  `<meta property="og:title" content="T"><meta property="og:image" content="../images/a.png">`,
+			title: "T", image: "https://example.com/images/a.png",
+		},
+		{
+			name:  "javascript image",
+			page:  `<meta property="og:title" content="T"><meta property="og:image" content="javascript:alert(1)">`,
+			title: "T",
+		},
+		{
+			name: "nothing to preview",
+			page: `<html><body>Hello</body></html>`,
+		},
+	}
+	for _, test := range tests {
+		preview := parse(test.page, base)
+		if preview.Title != test.title || preview.Description != test.description || preview.Image != test.image {
+			t.Errorf("%s: parsed %q, %q, %q, want %q, %q, %q", test.name,
+				preview.Title, preview.Description, preview.Image, test.title, test.description, test.image)
+		}
+	}
+}
+
+func TestParseCutsLongFields(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	page := `<meta property="og:title" content="` + strings.Repeat("é", maxTitleLength+10) + `">`
+	if title := parse(page, base).Title; title != strings.Repeat("é", maxTitleLength) {
+		t.Errorf("title has %d characters, want %d", len([]rune(title)), maxTitleLength)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post?id=1")
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"", ""},
+		{"https://cdn.example.com/a.png", "https://cdn.example.com/a.png"},
+		{"/a.png", "https://example.com/a.png"},
+		{"a.png", "https://example.com/blog/a.png"},
+		{"//cdn.example.com/a.png", "https://cdn.example.com/a.png"},
+		{"javascript:alert(1)", ""},
+		{"JavaScript:alert(1)", ""},
+		{"data:image/png;base64,AAAA", ""},
+		{"ftp://example.com/a.png", ""},
+		{"https://example.com/" + strings.Repeat("a", maxURLLength), ""},
+	}
+	for _, test := range tests {
+		if got := resolve(base, test.image); got != test.want {
+			t.Errorf("resolve(%q) = %q, want %q", test.image, got, test.want)
+		}
+	}
+}
+
+func TestExtractURLs(t *testing.T) {
+	links := ExtractURLs("See https://a.example.com/x, and http://b.example.com/y. Again https://a.example.com/x ftp://c.example.com")
+	if len(links) != 2 || links[0] != "https://a.example.com/x" || links[1] != "http://b.example.com/y" {
+		t.Errorf("extracted %q", links)
+	}
+}
@@ -0,0 +1,94 @@
+package previews
+
+import (
+	"api/src/models"
+	"context"
+	"log"
+	"sync"
+)
+
+const (
+	workers   = 2
+	queueSize = 256
+)
+
+// StoreFunc saves the result of fetching a link, error is set when no preview could be built
+type StoreFunc func(link string, preview models.LinkPreview, error error)
+
+// Worker fetches the previews of links in the background
+type Worker struct {
+	fetcher *Fetcher
+	store   StoreFunc
+	queue   chan string
+	mutex   sync.Mutex
+	queued  map[string]bool
+}
+
+// Default is the worker used by the API, it is nil until Start is called
+var Default *Worker
+
+//NewWorker creates a worker that fetches the links with the fetcher and hands the results to store
+func NewWorker(fetcher *Fetcher, store StoreFunc) *Worker {
+	return &Worker{
+		fetcher: fetcher,
+		store:   store,
+		queue:   make(chan string, queueSize),
+		queued:  map[string]bool{},
+	}
+}
+
+//Start creates the default worker and its goroutines
+func Start(store StoreFunc) {
+	Default = NewWorker(NewFetcher(false), store)
+	Default.Run(context.Background())
+}
+
+//Run starts the goroutines of the worker, they stop with the context
+func (worker *Worker) Run(ctx context.Context) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case link := <-worker.queue:
+					worker.process(ctx, link)
+				}
+			}
+		}()
+	}
+}
+
+//Enqueue schedules the links to be fetched. Links already waiting are skipped and links that
+//don't fit in the queue are dropped, they stay pending and are queued again on the next start
+func (worker *Worker) Enqueue(links ...string) {
+	worker.mutex.Lock()
+	defer worker.mutex.Unlock()
+	for _, link := range links {
+		if worker.queued[link] {
+			continue
+		}
+		select {
+		case worker.queue <- link:
+			worker.queued[link] = true
+		default:
+			log.Printf("link preview queue is full, dropping %s", link)
+		}
+	}
+}
+
+//Enqueue schedules the links in the default worker, doing nothing when it was not started
+func Enqueue(links ...string) {
+	if Default != nil {
+		Default.Enqueue(links...)
+	}
+}
+
+func (worker *Worker) process(ctx context.Context, link string) {
+	preview, error := worker.fetcher.Fetch(ctx, link)
+	worker.store(link, preview, error)
+
+	worker.mutex.Lock()
+	delete(worker.queued, link)
+	worker.mutex.Unlock()
+}
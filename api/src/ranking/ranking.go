@@ -0,0 +1,48 @@
+package ranking
+
+import (
+	"api/src/config"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Weights of what makes a post rank higher
+const (
+	Publication = 1.0
+	Like        = 1.0
+	Reply       = 2.0
+	Repost      = 3.0
+)
+
+// epoch is the time scores are measured from
+var epoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// HalfLife is how long it takes an engagement to weigh half as much as a new one. The scores stored
+// are only comparable when computed with the same half life, so a new one means rescoring every post
+var HalfLife = 12 * time.Hour
+
+//Load sets the half life of the configuration
+func Load() {
+	HalfLife = config.RankHalfLife
+}
+
+//Score is the score of a post with a single engagement of the weight at the time.
+//
+//The score of a post is the sum of the weights of its engagements, each one doubled every half life
+//after the epoch, so newer engagements count more. It is kept as a base 2 logarithm, where adding an
+//engagement is cheap and numbers stay small, and is the same as decaying every score every half life
+func Score(weight float64, at time.Time) float64 {
+	return math.Log2(weight) + at.Sub(epoch).Hours()/HalfLife.Hours()
+}
+
+//Expression is the SQL expression of Score for the weight at the time in the column, to compute scores in the database
+func Expression(weight float64, column string) string {
+	return fmt.Sprintf(`log2(%g) + (unix_timestamp(%s) - %d) / %g`, weight, column, epoch.Unix(), HalfLife.Seconds())
+}
+
+//Add is the SQL expression of the score in the column after adding the score given twice as its arguments.
+//It adds the scores out of the logarithm without overflowing: log2(2^a + 2^b) = max(a, b) + log2(1 + 2^-|a - b|)
+func Add(column string) string {
+	return `greatest(` + column + `, ?) + log2(1 + pow(2, -abs(` + column + ` - ?)))`
+}
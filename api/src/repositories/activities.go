@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// activityColumns are the columns selected when reading activities, a is the activity, actor its actor and su the user it involves
+const activityColumns = `a.id, a.actor_id, actor.nick, a.type, coalesce(a.user_id, 0), coalesce(su.nick, ''), coalesce(a.post_id, 0), a.createdAt`
+
+// Activities represents a repository of the append-only log of what users do
+type Activities struct {
+	db *sql.DB
+}
+
+//NewActivityRepository creates an activity repository
+func NewActivityRepository(db *sql.DB) *Activities {
+	return &Activities{db}
+}
+
+//Record appends the activity to the log
+func (repository Activities) Record(activity models.Activity) error {
+	statement, error := repository.db.Prepare("insert into activities (actor_id, type, user_id, post_id) values (?, ?, ?, ?)")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(activity.ActorID, activity.Type, nullableID(activity.UserID), nullableID(activity.PostID)); error != nil {
+		return error
+	}
+	return nil
+}
+
+//FetchByActor fetches the activities of the actor the viewer is allowed to see, the latest first. The activities of
+//private users are only seen by their followers, and activities about posts the viewer can't see or about users
+//blocked by or blocking the viewer are left out
+func (repository Activities) FetchByActor(actorID, viewerID, limit, offset uint64) ([]models.Activity, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+activityColumns+` from activities a
+	inner join users actor on actor.id = a.actor_id
+	left join users su on su.id = a.user_id
+	left join posts p on p.id = a.post_id
+	left join users u on u.id = p.author_id
+	where a.actor_id = ?
+	and (a.actor_id = ? or actor.private = false or exists (select 1 from followers af where af.user_id = a.actor_id and af.follower_id = ?))
+	and (a.user_id is null or `+notBlockedWith("a.user_id")+`)
+	and (a.post_id is null or `+visibility+`)
+	order by a.id desc limit ? offset ?`,
+		append(append([]interface{}{actorID, viewerID, viewerID, viewerID, viewerID}, arguments...), limit, offset)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanActivities(lines)
+}
+
+func scanActivities(lines *sql.Rows) ([]models.Activity, error) {
+	var activities []models.Activity
+	for lines.Next() {
+		var activity models.Activity
+		if error := lines.Scan(
+			&activity.ID,
+			&activity.ActorID,
+			&activity.ActorNick,
+			&activity.Type,
+			&activity.UserID,
+			&activity.UserNick,
+			&activity.PostID,
+			&activity.CreatedAt,
+		); error != nil {
+			return nil, error
+		}
+		activities = append(activities, activity)
+	}
+	return activities, nil
+}
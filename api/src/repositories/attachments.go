@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// attachmentColumns are the columns selected when reading attachments
+const attachmentColumns = "id, post_id, content_type, size, width, height, storage_key, coalesce(thumbnail_key, ''), createdAt"
+
+// Attachments represents an attachment repository
+type Attachments struct {
+	db *sql.DB
+}
+
+//NewAttachmentRepository creates an attachment repository
+func NewAttachmentRepository(db *sql.DB) *Attachments {
+	return &Attachments{db}
+}
+
+//Create inserts the attachment of a post. The post is locked while its files are counted, so the
+//returned ID is 0 when it already has maxAttachments files, even with concurrent uploads
+func (repository Attachments) Create(attachment models.Attachment, maxAttachments uint64) (uint64, error) {
+	var thumbnailKey interface{}
+	if attachment.ThumbnailKey != "" {
+		thumbnailKey = attachment.ThumbnailKey
+	}
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return 0, error
+	}
+	defer transaction.Rollback()
+
+	if _, error = transaction.Exec(`select id from posts where id = ? for update`, attachment.PostID); error != nil {
+		return 0, error
+	}
+	var count uint64
+	if error = transaction.QueryRow("select count(*) from attachments where post_id = ?", attachment.PostID).Scan(&count); error != nil {
+		return 0, error
+	}
+	if count >= maxAttachments {
+		return 0, nil
+	}
+	result, error := transaction.Exec(
+		"insert into attachments (post_id, content_type, size, width, height, storage_key, thumbnail_key) values (?, ?, ?, ?, ?, ?, ?)",
+		attachment.PostID, attachment.ContentType, attachment.Size, attachment.Width, attachment.Height, attachment.Key, thumbnailKey,
+	)
+	if error != nil {
+		return 0, error
+	}
+	lastInsertID, error := result.LastInsertId()
+	if error != nil {
+		return 0, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return 0, error
+	}
+	return uint64(lastInsertID), nil
+}
+
+//FetchByID fetches an attachment of a post
+func (repository Attachments) FetchByID(postID, attachmentID uint64) (models.Attachment, error) {
+	lines, error := repository.db.Query("select "+attachmentColumns+" from attachments where id = ? and post_id = ?", attachmentID, postID)
+	if error != nil {
+		return models.Attachment{}, error
+	}
+	defer lines.Close()
+
+	attachments, error := scanAttachments(lines)
+	if error != nil || len(attachments) == 0 {
+		return models.Attachment{}, error
+	}
+	return attachments[0], nil
+}
+
+//FetchByPosts fetches the attachments of the posts grouped by post
+func (repository Attachments) FetchByPosts(postIDs []uint64) (map[uint64][]models.Attachment, error) {
+	attachmentsByPost := map[uint64][]models.Attachment{}
+	if len(postIDs) == 0 {
+		return attachmentsByPost, nil
+	}
+	arguments := make([]interface{}, len(postIDs))
+	for i, postID := range postIDs {
+		arguments[i] = postID
+	}
+	lines, error := repository.db.Query("select "+attachmentColumns+" from attachments where post_id in ("+placeholders(len(postIDs))+") order by id", arguments...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	attachments, error := scanAttachments(lines)
+	if error != nil {
+		return nil, error
+	}
+	for _, attachment := range attachments {
+		attachmentsByPost[attachment.PostID] = append(attachmentsByPost[attachment.PostID], attachment)
+	}
+	return attachmentsByPost, nil
+}
+
+//Count counts the attachments of a post
+func (repository Attachments) Count(postID uint64) (uint64, error) {
+	var count uint64
+	if error := repository.db.QueryRow("select count(*) from attachments where post_id = ?", postID).Scan(&count); error != nil {
+		return 0, error
+	}
+	return count, nil
+}
+
+//Delete removes an attachment
+func (repository Attachments) Delete(attachmentID uint64) error {
+	statement, error := repository.db.Prepare("delete from attachments where id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(attachmentID); error != nil {
+		return error
+	}
+	return nil
+}
+
+func scanAttachments(lines *sql.Rows) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	for lines.Next() {
+		var attachment models.Attachment
+		if error := lines.Scan(
+			&attachment.ID,
+			&attachment.PostID,
+			&attachment.ContentType,
+			&attachment.Size,
+			&attachment.Width,
+			&attachment.Height,
+			&attachment.Key,
+			&attachment.ThumbnailKey,
+			&attachment.CreatedAt,
+		); error != nil {
+			return nil, error
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
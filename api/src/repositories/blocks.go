@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// Blocks represents a block repository
+type Blocks struct {
+	db *sql.DB
+}
+
+//NewBlockRepository creates a block repository
+func NewBlockRepository(db *sql.DB) *Blocks {
+	return &Blocks{db}
+}
+
+//Block stops the users from seeing and following each other, removing the existing follows and follow requests in both directions
+func (repository Blocks) Block(userID, blockedID uint64) error {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return error
+	}
+	defer transaction.Rollback()
+
+	if _, error = transaction.Exec("insert ignore into blocks (user_id, blocked_id) values (?, ?)", userID, blockedID); error != nil {
+		return error
+	}
+	if _, error = transaction.Exec(
+		"delete from followers where (user_id = ? and follower_id = ?) or (user_id = ? and follower_id = ?)",
+		userID, blockedID, blockedID, userID,
+	); error != nil {
+		return error
+	}
+	if _, error = transaction.Exec(
+		"delete from follow_requests where (user_id = ? and requester_id = ?) or (user_id = ? and requester_id = ?)",
+		userID, blockedID, blockedID, userID,
+	); error != nil {
+		return error
+	}
+	return transaction.Commit()
+}
+
+//Unblock removes the block, the follows removed by it are not restored
+func (repository Blocks) Unblock(userID, blockedID uint64) error {
+	statement, error := repository.db.Prepare("delete from blocks where user_id = ? and blocked_id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID, blockedID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//IsBlocked tells whether any of the users blocked the other
+func (repository Blocks) IsBlocked(userID, otherUserID uint64) (bool, error) {
+	var blocked bool
+	if error := repository.db.QueryRow(
+		"select exists (select 1 from blocks where (user_id = ? and blocked_id = ?) or (user_id = ? and blocked_id = ?))",
+		userID, otherUserID, otherUserID, userID,
+	).Scan(&blocked); error != nil {
+		return false, error
+	}
+	return blocked, nil
+}
+
+//FetchBlocked fetches the users blocked by the user
+func (repository Blocks) FetchBlocked(userID uint64) ([]models.User, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u
+	inner join blocks b on b.blocked_id = u.id
+	where b.user_id = ? order by b.createdAt desc`, userID, userID)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanUsers(lines)
+}
+
+//notBlockedWith is a condition that holds when neither the viewer nor the user in the column blocked
+//the other. It expects the viewer ID twice as its arguments
+func notBlockedWith(column string) string {
+	return `not exists (select 1 from blocks b where (b.user_id = ? and b.blocked_id = ` + column + `) or (b.user_id = ` + column + ` and b.blocked_id = ?))`
+}
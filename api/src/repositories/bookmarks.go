@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// Bookmarks represents a bookmark repository
+type Bookmarks struct {
+	db *sql.DB
+}
+
+//NewBookmarkRepository creates a bookmark repository
+func NewBookmarkRepository(db *sql.DB) *Bookmarks {
+	return &Bookmarks{db}
+}
+
+//Add saves the post in the bookmarks of the user, moving it to the collection if it was already bookmarked
+func (repository Bookmarks) Add(userID, postID, collectionID uint64) error {
+	statement, error := repository.db.Prepare(`insert into bookmarks (user_id, post_id, collection_id) values (?, ?, ?)
+	on duplicate key update collection_id = values(collection_id)`)
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID, postID, nullableID(collectionID)); error != nil {
+		return error
+	}
+	return nil
+}
+
+//Remove takes the post out of the bookmarks of the user
+func (repository Bookmarks) Remove(userID, postID uint64) error {
+	statement, error := repository.db.Prepare("delete from bookmarks where user_id = ? and post_id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID, postID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//Fetch fetches the bookmarked posts of the user the user can still see, the last bookmarked first.
+//When the collection is not 0 only its posts are fetched
+func (repository Bookmarks) Fetch(userID, collectionID, limit, offset uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(userID)
+	lines, error := repository.db.Query(`select `+postColumns+` from bookmarks b
+	inner join posts p on p.id = b.post_id
+	inner join users u on u.id = p.author_id
+	where b.user_id = ? and (? = 0 or b.collection_id = ?) and `+visibility+`
+	order by b.createdAt desc, p.id desc limit ? offset ?`,
+		append(append([]interface{}{userID, collectionID, collectionID}, arguments...), limit, offset)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanPosts(lines)
+}
+
+//BookmarkedPosts tells which of the posts the user bookmarked
+func (repository Bookmarks) BookmarkedPosts(userID uint64, postIDs []uint64) (map[uint64]bool, error) {
+	bookmarked := map[uint64]bool{}
+	if userID == 0 || len(postIDs) == 0 {
+		return bookmarked, nil
+	}
+	arguments := []interface{}{userID}
+	for _, postID := range postIDs {
+		arguments = append(arguments, postID)
+	}
+	lines, error := repository.db.Query(`select post_id from bookmarks where user_id = ? and post_id in (`+placeholders(len(postIDs))+`)`, arguments...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	postIDs, error = scanIDs(lines)
+	if error != nil {
+		return nil, error
+	}
+	for _, postID := range postIDs {
+		bookmarked[postID] = true
+	}
+	return bookmarked, nil
+}
+
+//CreateCollection inserts a bookmark collection and returns its ID
+func (repository Bookmarks) CreateCollection(collection models.Collection) (uint64, error) {
+	statement, error := repository.db.Prepare("insert into bookmark_collections (user_id, name) values (?, ?)")
+	if error != nil {
+		return 0, error
+	}
+	defer statement.Close()
+
+	result, error := statement.Exec(collection.UserID, collection.Name)
+	if error != nil {
+		return 0, error
+	}
+	lastInsertID, error := result.LastInsertId()
+	if error != nil {
+		return 0, error
+	}
+	return uint64(lastInsertID), nil
+}
+
+//FetchCollections fetches the bookmark collections of the user with how many bookmarks they have, by name
+func (repository Bookmarks) FetchCollections(userID uint64) ([]models.Collection, error) {
+	lines, error := repository.db.Query(`select c.id, c.user_id, c.name, count(b.post_id), c.createdAt
+	from bookmark_collections c left join bookmarks b on b.collection_id = c.id
+	where c.user_id = ? group by c.id order by c.name, c.id`, userID)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var collections []models.Collection
+	for lines.Next() {
+		var collection models.Collection
+		if error = lines.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.Bookmarks, &collection.CreatedAt); error != nil {
+			return nil, error
+		}
+		collections = append(collections, collection)
+	}
+	return collections, nil
+}
+
+//FetchCollection fetches a bookmark collection, its ID is 0 when it doesn't exist
+func (repository Bookmarks) FetchCollection(collectionID uint64) (models.Collection, error) {
+	var collection models.Collection
+	error := repository.db.QueryRow(`select c.id, c.user_id, c.name, count(b.post_id), c.createdAt
+	from bookmark_collections c left join bookmarks b on b.collection_id = c.id
+	where c.id = ? group by c.id`, collectionID,
+	).Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.Bookmarks, &collection.CreatedAt)
+	if error != nil && error != sql.ErrNoRows {
+		return models.Collection{}, error
+	}
+	return collection, nil
+}
+
+//RenameCollection changes the name of a bookmark collection
+func (repository Bookmarks) RenameCollection(collectionID uint64, name string) error {
+	statement, error := repository.db.Prepare("update bookmark_collections set name = ? where id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(name, collectionID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//DeleteCollection deletes a bookmark collection, its bookmarks are kept without collection
+func (repository Bookmarks) DeleteCollection(collectionID uint64) error {
+	statement, error := repository.db.Prepare("delete from bookmark_collections where id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(collectionID); error != nil {
+		return error
+	}
+	return nil
+}
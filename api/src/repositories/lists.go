@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// listColumns are the columns selected when reading lists, l is the list and o its owner
+const listColumns = `l.id, l.owner_id, o.nick, l.name, l.description, l.private,
+	(select count(*) from list_members lm where lm.list_id = l.id), l.createdAt`
+
+// Lists represents a list repository
+type Lists struct {
+	db *sql.DB
+}
+
+//NewListRepository creates a list repository
+func NewListRepository(db *sql.DB) *Lists {
+	return &Lists{db}
+}
+
+//Create inserts a list and returns its ID
+func (repository Lists) Create(list models.List) (uint64, error) {
+	statement, error := repository.db.Prepare("insert into lists (owner_id, name, description, private) values (?, ?, ?, ?)")
+	if error != nil {
+		return 0, error
+	}
+	defer statement.Close()
+
+	result, error := statement.Exec(list.OwnerID, list.Name, list.Description, list.Private)
+	if error != nil {
+		return 0, error
+	}
+	lastInsertID, error := result.LastInsertId()
+	if error != nil {
+		return 0, error
+	}
+	return uint64(lastInsertID), nil
+}
+
+//FetchByID fetches a list, its ID is 0 when it doesn't exist
+func (repository Lists) FetchByID(listID uint64) (models.List, error) {
+	lines, error := repository.db.Query(`select `+listColumns+` from lists l inner join users o on o.id = l.owner_id where l.id = ?`, listID)
+	if error != nil {
+		return models.List{}, error
+	}
+	defer lines.Close()
+
+	var list models.List
+	if lines.Next() {
+		if error = scanList(lines, &list); error != nil {
+			return models.List{}, error
+		}
+	}
+	return list, nil
+}
+
+//FetchByOwner fetches the lists of the owner by name, their private lists only when the viewer is the owner
+func (repository Lists) FetchByOwner(ownerID, viewerID uint64) ([]models.List, error) {
+	lines, error := repository.db.Query(`select `+listColumns+` from lists l inner join users o on o.id = l.owner_id
+	where l.owner_id = ? and (l.private = false or l.owner_id = ?) order by l.name, l.id`, ownerID, viewerID)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var lists []models.List
+	for lines.Next() {
+		var list models.List
+		if error = scanList(lines, &list); error != nil {
+			return nil, error
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+//Update changes the name, description and privacy of the list
+func (repository Lists) Update(listID uint64, list models.List) error {
+	statement, error := repository.db.Prepare("update lists set name = ?, description = ?, private = ? where id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(list.Name, list.Description, list.Private, listID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//Delete deletes the list with its members
+func (repository Lists) Delete(listID uint64) error {
+	statement, error := repository.db.Prepare("delete from lists where id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(listID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//AddMember adds the user to the list. It returns false when the list is full, adding a member twice changes nothing
+func (repository Lists) AddMember(listID, userID uint64, maxMembers int) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	if _, error = transaction.Exec(`select id from lists where id = ? for update`, listID); error != nil {
+		return false, error
+	}
+	var member bool
+	var members int
+	if error = transaction.QueryRow(`select coalesce(sum(user_id = ?), 0) > 0, count(*) from list_members where list_id = ?`,
+		userID, listID,
+	).Scan(&member, &members); error != nil {
+		return false, error
+	}
+	if member {
+		return true, nil
+	}
+	if members >= maxMembers {
+		return false, nil
+	}
+	if _, error = transaction.Exec(`insert into list_members (list_id, user_id) values (?, ?)`, listID, userID); error != nil {
+		return false, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//RemoveMember takes the user out of the list
+func (repository Lists) RemoveMember(listID, userID uint64) error {
+	statement, error := repository.db.Prepare("delete from list_members where list_id = ? and user_id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(listID, userID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//FetchMembers fetches the members of the list, the last added first. Users blocked by or blocking the viewer are left out
+func (repository Lists) FetchMembers(listID, viewerID, limit, offset uint64) ([]models.User, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u
+	inner join list_members lm on lm.user_id = u.id
+	where lm.list_id = ? and `+notBlockedWith("u.id")+`
+	order by lm.createdAt desc, u.id limit ? offset ?`, viewerID, listID, viewerID, viewerID, limit, offset)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanUsers(lines)
+}
+
+//FetchPosts fetches the timeline of the list: the posts of its members the viewer is allowed to see, the latest first
+func (repository Lists) FetchPosts(listID, viewerID, limit, offset uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p
+	inner join users u on u.id = p.author_id
+	inner join list_members lm on lm.user_id = p.author_id
+	where lm.list_id = ? and `+visibility+`
+	order by p.createdAt desc, p.id desc limit ? offset ?`,
+		append(append([]interface{}{listID}, arguments...), limit, offset)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanPosts(lines)
+}
+
+func scanList(lines *sql.Rows, list *models.List) error {
+	return lines.Scan(
+		&list.ID,
+		&list.OwnerID,
+		&list.OwnerNick,
+		&list.Name,
+		&list.Description,
+		&list.Private,
+		&list.Members,
+		&list.CreatedAt,
+	)
+}
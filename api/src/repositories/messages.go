@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+// Messages represents a direct message repository
+type Messages struct {
+	db *sql.DB
+}
+
+//NewMessageRepository creates a direct message repository
+func NewMessageRepository(db *sql.DB) *Messages {
+	return &Messages{db}
+}
+
+//CanMessage tells whether the sender is allowed to message the recipient: none of them blocked the other
+//and they follow each other or the recipient allows DMs
+func (repository Messages) CanMessage(senderID, recipientID uint64) (bool, error) {
+	lines, error := repository.db.Query(`select u.allow_dms or (
+		exists (select 1 from followers f where f.user_id = u.id and f.follower_id = ?)
+		and exists (select 1 from followers f where f.user_id = ? and f.follower_id = u.id)
+	) from users u where u.id = ? and `+notBlockedWith("u.id"), senderID, senderID, recipientID, senderID, senderID)
+	if error != nil {
+		return false, error
+	}
+	defer lines.Close()
+
+	var allowed bool
+	if lines.Next() {
+		if error = lines.Scan(&allowed); error != nil {
+			return false, error
+		}
+	}
+	return allowed, nil
+}
+
+//FetchOrCreateConversation returns the conversation between two users, creating it when needed
+func (repository Messages) FetchOrCreateConversation(userID, otherUserID uint64) (uint64, error) {
+	userOneID, userTwoID := userID, otherUserID
+	if userOneID > userTwoID {
+		userOneID, userTwoID = userTwoID, userOneID
+	}
+	if _, error := repository.db.Exec("insert ignore into conversations (user_one_id, user_two_id) values (?, ?)", userOneID, userTwoID); error != nil {
+		return 0, error
+	}
+
+	var conversationID uint64
+	if error := repository.db.QueryRow(
+		"select id from conversations where user_one_id = ? and user_two_id = ?", userOneID, userTwoID,
+	).Scan(&conversationID); error != nil {
+		return 0, error
+	}
+	return conversationID, nil
+}
+
+//FetchOtherParticipant returns the other user of a conversation of the user, or 0 when the user is not part of it
+func (repository Messages) FetchOtherParticipant(conversationID, userID uint64) (uint64, error) {
+	lines, error := repository.db.Query(`select if(user_one_id = ?, user_two_id, user_one_id) from conversations
+	where id = ? and (user_one_id = ? or user_two_id = ?)`, userID, conversationID, userID, userID)
+	if error != nil {
+		return 0, error
+	}
+	defer lines.Close()
+
+	var otherUserID uint64
+	if lines.Next() {
+		if error = lines.Scan(&otherUserID); error != nil {
+			return 0, error
+		}
+	}
+	return otherUserID, nil
+}
+
+//FetchConversations fetches the conversations of the user, the most recent first
+func (repository Messages) FetchConversations(userID, limit, offset uint64) ([]models.Conversation, error) {
+	lines, error := repository.db.Query(`select c.id, u.id, u.nick, c.updatedAt,
+	m.id, m.sender_id, m.content, m.readAt is not null, m.createdAt,
+	(select count(*) from messages unread where unread.conversation_id = c.id and unread.sender_id <> ? and unread.readAt is null)
+	from conversations c
+	inner join users u on u.id = if(c.user_one_id = ?, c.user_two_id, c.user_one_id)
+	inner join messages m on m.id = (select max(last.id) from messages last where last.conversation_id = c.id)
+	where c.user_one_id = ? or c.user_two_id = ?
+	order by c.updatedAt desc, c.id desc limit ? offset ?`, userID, userID, userID, userID, limit, offset)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var conversations []models.Conversation
+	for lines.Next() {
+		var conversation models.Conversation
+		var message models.Message
+		if error = lines.Scan(
+			&conversation.ID,
+			&conversation.UserID,
+			&conversation.UserNick,
+			&conversation.UpdatedAt,
+			&message.ID,
+			&message.SenderID,
+			&message.Content,
+			&message.Read,
+			&message.CreatedAt,
+			&conversation.Unread,
+		); error != nil {
+			return nil, error
+		}
+		message.ConversationID = conversation.ID
+		conversation.LastMessage = &message
+		conversations = append(conversations, conversation)
+	}
+	return conversations, nil
+}
+
+//FetchMessages fetches the messages of a conversation, the newest first
+func (repository Messages) FetchMessages(conversationID, limit, offset uint64) ([]models.Message, error) {
+	lines, error := repository.db.Query(`select id, conversation_id, sender_id, content, readAt is not null, createdAt
+	from messages where conversation_id = ?
+	order by id desc limit ? offset ?`, conversationID, limit, offset)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var messages []models.Message
+	for lines.Next() {
+		var message models.Message
+		if error = lines.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.SenderID,
+			&message.Content,
+			&message.Read,
+			&message.CreatedAt,
+		); error != nil {
+			return nil, error
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+//Send inserts the message in its conversation
+func (repository Messages) Send(message models.Message) (uint64, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return 0, error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec("insert into messages (conversation_id, sender_id, content) values (?, ?, ?)", message.ConversationID, message.SenderID, message.Content)
+	if error != nil {
+		return 0, error
+	}
+	lastInsertID, error := result.LastInsertId()
+	if error != nil {
+		return 0, error
+	}
+	if _, error = transaction.Exec("update conversations set updatedAt = ? where id = ?", time.Now(), message.ConversationID); error != nil {
+		return 0, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return 0, error
+	}
+	return uint64(lastInsertID), nil
+}
+
+//MarkRead marks the messages the user received in the conversation as read
+func (repository Messages) MarkRead(conversationID, userID uint64) error {
+	statement, error := repository.db.Prepare("update messages set readAt = current_timestamp() where conversation_id = ? and sender_id <> ? and readAt is null")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(conversationID, userID); error != nil {
+		return error
+	}
+	return nil
+}
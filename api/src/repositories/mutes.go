@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// Mutes represents a mute repository
+type Mutes struct {
+	db *sql.DB
+}
+
+//NewMuteRepository creates a mute repository
+func NewMuteRepository(db *sql.DB) *Mutes {
+	return &Mutes{db}
+}
+
+//Mute hides the posts of the muted user from the timeline of the user
+func (repository Mutes) Mute(userID, mutedID uint64) error {
+	statement, error := repository.db.Prepare("insert ignore into mutes (user_id, muted_id) values (?, ?)")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID, mutedID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//Unmute shows the posts of the muted user in the timeline of the user again
+func (repository Mutes) Unmute(userID, mutedID uint64) error {
+	statement, error := repository.db.Prepare("delete from mutes where user_id = ? and muted_id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID, mutedID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//FetchMuted fetches the users muted by the user
+func (repository Mutes) FetchMuted(userID uint64) ([]models.User, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u
+	inner join mutes m on m.muted_id = u.id
+	where m.user_id = ? order by m.createdAt desc`, userID, userID)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanUsers(lines)
+}
+
+//notMutedBy is a condition that holds when the viewer didn't mute the user in the column. It expects the viewer ID as its argument
+func notMutedBy(column string) string {
+	return `not exists (select 1 from mutes m where m.user_id = ? and m.muted_id = ` + column + `)`
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"fmt"
+)
+
+// preferenceColumns maps each type of notification to the column of the users table that enables it,
+// the types without a column can't be disabled
+var preferenceColumns = map[string]string{
+	models.NotificationFollow:         "notify_follow",
+	models.NotificationFollowRequest:  "notify_follow",
+	models.NotificationFollowAccepted: "notify_follow",
+	models.NotificationLike:           "notify_like",
+	models.NotificationComment:        "notify_comment",
+	models.NotificationMention:        "notify_mention",
+	models.NotificationReportResolved: "",
+}
+
+// Notifications represents a notification repository
+type Notifications struct {
+	db *sql.DB
+}
+
+//NewNotificationRepository creates a notification repository
+func NewNotificationRepository(db *sql.DB) *Notifications {
+	return &Notifications{db}
+}
+
+//Create inserts the notification unless the user disabled its type, is the actor, has a block
+//with the actor or still has the same notification unread. It returns 0 when nothing was inserted
+func (repository Notifications) Create(notification models.Notification) (uint64, error) {
+	column, exists := preferenceColumns[notification.Type]
+	if !exists {
+		return 0, fmt.Errorf("Unknown notification type %s", notification.Type)
+	}
+	enabled := "true"
+	if column != "" {
+		enabled = "u." + column
+	}
+	actorID := nullableID(notification.ActorID)
+	postID := nullableID(notification.PostID)
+
+	result, error := repository.db.Exec(`insert into notifications (user_id, actor_id, type, post_id)
+	select u.id, ?, ?, ? from users u
+	where u.id = ? and not u.id <=> ? and `+enabled+` = true
+	and `+notBlockedWith("u.id")+`
+	and not exists (
+		select 1 from notifications n
+		where n.user_id = u.id and n.actor_id <=> ? and n.type = ? and n.post_id <=> ? and n.readAt is null
+	)`,
+		actorID, notification.Type, postID,
+		notification.UserID, actorID,
+		actorID, actorID,
+		actorID, notification.Type, postID,
+	)
+	if error != nil {
+		return 0, error
+	}
+	if rowsAffected, error := result.RowsAffected(); error != nil || rowsAffected == 0 {
+		return 0, error
+	}
+	lastInsertID, error := result.LastInsertId()
+	if error != nil {
+		return 0, error
+	}
+	return uint64(lastInsertID), nil
+}
+
+//Fetch fetches the notifications of the user, newest first
+func (repository Notifications) Fetch(userID uint64, unreadOnly bool, limit, offset uint64) ([]models.Notification, error) {
+	lines, error := repository.db.Query(`select n.id, coalesce(n.actor_id, 0), coalesce(u.nick, ''), n.type, coalesce(n.post_id, 0), n.readAt is not null, n.createdAt
+	from notifications n left join users u on u.id = n.actor_id
+	where n.user_id = ? and (? = false or n.readAt is null)
+	order by n.id desc limit ? offset ?`, userID, unreadOnly, limit, offset)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var notifications []models.Notification
+	for lines.Next() {
+		var notification models.Notification
+		if error = lines.Scan(
+			&notification.ID,
+			&notification.ActorID,
+			&notification.ActorNick,
+			&notification.Type,
+			&notification.PostID,
+			&notification.Read,
+			&notification.CreatedAt,
+		); error != nil {
+			return nil, error
+		}
+		notification.UserID = userID
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+//CountUnread counts the notifications the user didn't read
+func (repository Notifications) CountUnread(userID uint64) (uint64, error) {
+	var unread uint64
+	if error := repository.db.QueryRow("select count(*) from notifications where user_id = ? and readAt is null", userID).Scan(&unread); error != nil {
+		return 0, error
+	}
+	return unread, nil
+}
+
+//MarkRead marks a notification of the user as read
+func (repository Notifications) MarkRead(userID, notificationID uint64) error {
+	statement, error := repository.db.Prepare("update notifications set readAt = current_timestamp() where id = ? and user_id = ? and readAt is null")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(notificationID, userID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//MarkAllRead marks all notifications of the user as read
+func (repository Notifications) MarkAllRead(userID uint64) error {
+	statement, error := repository.db.Prepare("update notifications set readAt = current_timestamp() where user_id = ? and readAt is null")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//FetchPreferences fetches which types of notification the user receives
+func (repository Notifications) FetchPreferences(userID uint64) (models.NotificationPreferences, error) {
+	var preferences models.NotificationPreferences
+	if error := repository.db.QueryRow(
+		"select notify_follow, notify_like, notify_comment, notify_mention from users where id = ?", userID,
+	).Scan(
+		&preferences.Follow,
+		&preferences.Like,
+		&preferences.Comment,
+		&preferences.Mention,
+	); error != nil {
+		return models.NotificationPreferences{}, error
+	}
+	return preferences, nil
+}
+
+//UpdatePreferences changes which types of notification the user receives
+func (repository Notifications) UpdatePreferences(userID uint64, preferences models.NotificationPreferences) error {
+	statement, error := repository.db.Prepare("update users set notify_follow = ?, notify_like = ?, notify_comment = ?, notify_mention = ? where id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(preferences.Follow, preferences.Like, preferences.Comment, preferences.Mention, userID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//nullableID turns the zero ID into NULL
+func nullableID(ID uint64) interface{} {
+	if ID == 0 {
+		return nil
+	}
+	return ID
+}
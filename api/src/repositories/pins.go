@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"database/sql"
+)
+
+// Pins represents a pinned post repository
+type Pins struct {
+	db *sql.DB
+}
+
+//NewPinRepository creates a pinned post repository
+func NewPinRepository(db *sql.DB) *Pins {
+	return &Pins{db}
+}
+
+//Pin pins the post to the profile of the user after the posts already pinned. It returns false when
+//the user already pinned as many posts as allowed. Pinning a pinned post again changes nothing
+func (repository Pins) Pin(userID, postID uint64, maxPinned int) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	pinnedIDs, error := lockPinned(transaction, userID)
+	if error != nil {
+		return false, error
+	}
+	for _, pinnedID := range pinnedIDs {
+		if pinnedID == postID {
+			return true, nil
+		}
+	}
+	if len(pinnedIDs) >= maxPinned {
+		return false, nil
+	}
+	if _, error = transaction.Exec(`insert into pinned_posts (user_id, post_id, position)
+	select ?, ?, coalesce(max(position) + 1, 0) from pinned_posts where user_id = ?`, userID, postID, userID); error != nil {
+		return false, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//Unpin removes the post from the pinned posts of the user
+func (repository Pins) Unpin(userID, postID uint64) error {
+	statement, error := repository.db.Prepare("delete from pinned_posts where user_id = ? and post_id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID, postID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//Reorder sets the order of the pinned posts of the user. It returns false when the posts are not
+//exactly the ones the user pinned
+func (repository Pins) Reorder(userID uint64, postIDs []uint64) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	pinnedIDs, error := lockPinned(transaction, userID)
+	if error != nil {
+		return false, error
+	}
+	if len(pinnedIDs) != len(postIDs) {
+		return false, nil
+	}
+	pinned := map[uint64]bool{}
+	for _, pinnedID := range pinnedIDs {
+		pinned[pinnedID] = true
+	}
+	for position, postID := range postIDs {
+		if !pinned[postID] {
+			return false, nil
+		}
+		delete(pinned, postID)
+		if _, error = transaction.Exec(`update pinned_posts set position = ? where user_id = ? and post_id = ?`, position, userID, postID); error != nil {
+			return false, error
+		}
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//lockPinned locks the user, so their pinned posts change one request at a time, and returns the IDs of the pinned posts in order
+func lockPinned(transaction *sql.Tx, userID uint64) ([]uint64, error) {
+	if _, error := transaction.Exec(`select id from users where id = ? for update`, userID); error != nil {
+		return nil, error
+	}
+	lines, error := transaction.Query(`select post_id from pinned_posts where user_id = ? order by position`, userID)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanIDs(lines)
+}
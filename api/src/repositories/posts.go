@@ -2,9 +2,20 @@ package repositories
 
 import (
 	"api/src/models"
+	"api/src/ranking"
 	"database/sql"
+	"time"
 )
 
+// postColumns are the columns selected when reading posts, p is the post and u its author
+const postColumns = "p.id, p.title, p.content, p.author_id, p.visibility, p.status, p.publish_at, coalesce(p.quote_of_id, 0), coalesce(p.parent_id, 0), p.likes, p.reposts, p.quotes, p.replies, p.createdAt, u.nick"
+
+// maxThreadDepth is how many levels of a thread are read above or below a post
+const maxThreadDepth = 5
+
+// shareable is the condition for posts that can be reposted or quoted: they must be seen by anyone
+const shareable = "p.visibility in ('public', 'unlisted') and u.private = false"
+
 // Posts struct
 type Posts struct {
 	db *sql.DB
@@ -15,14 +26,24 @@ func NewPostRepository(db *sql.DB) *Posts {
 	return &Posts{db}
 }
 
-//Create inserts a new post in the database
+//Create inserts a new post in the database. Published posts get their tags and are counted in the
+//quoted post and in the post they reply to, drafts and scheduled posts only when they are published
 func (repository Posts) Create(post models.Post) (uint64, error) {
-	statement, error := repository.db.Prepare(`insert into posts (title, content, author_id) values (?, ?, ?)`)
+	transaction, error := repository.db.Begin()
 	if error != nil {
 		return 0, error
 	}
-	defer statement.Close()
-	result, error := statement.Exec(post.Title, post.Content, post.AuthorID)
+	defer transaction.Rollback()
+
+	var heldStatus interface{}
+	if post.HeldStatus != "" {
+		heldStatus = post.HeldStatus
+	}
+	result, error := transaction.Exec(
+		`insert into posts (title, content, author_id, visibility, status, publish_at, held_status, quote_of_id, parent_id, score) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		post.Title, post.Content, post.AuthorID, post.Visibility, post.Status, post.PublishAt, heldStatus, nullableID(post.QuoteOfID), nullableID(post.ParentID),
+		ranking.Score(ranking.Publication, time.Now()),
+	)
 	if error != nil {
 		return 0, error
 	}
@@ -30,40 +51,117 @@ func (repository Posts) Create(post models.Post) (uint64, error) {
 	if error != nil {
 		return 0, error
 	}
+	switch post.Status {
+	case models.StatusPublished:
+		if error = countReferences(transaction, uint64(lastInsertedID)); error != nil {
+			return 0, error
+		}
+		if error = saveTags(transaction, uint64(lastInsertedID), post.Tags); error != nil {
+			return 0, error
+		}
+	case models.StatusHeld:
+		if error = reportHeldPost(transaction, uint64(lastInsertedID), post.HeldReason); error != nil {
+			return 0, error
+		}
+	}
+	if error = transaction.Commit(); error != nil {
+		return 0, error
+	}
 	return uint64(lastInsertedID), nil
 }
 
 //FetchByID fetches a post by its id
 func (repository Posts) FetchByID(postID uint64) (models.Post, error) {
-	lines, error := repository.db.Query(`select p.*, u.nick from posts p inner join users u on u.id = p.author_id where p.id = ?`, postID)
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p inner join users u on u.id = p.author_id where p.id = ?`, postID)
+	if error != nil {
+		return models.Post{}, error
+	}
+	defer lines.Close()
+	var post models.Post
+	if lines.Next() {
+		if error = scanPost(lines, &post); error != nil {
+			return models.Post{}, error
+		}
+	}
+	return post, nil
+}
+
+//FetchVisible fetches a post by its id when the viewer is allowed to see it
+func (repository Posts) FetchVisible(postID, viewerID uint64) (models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p
+	inner join users u on u.id = p.author_id
+	where p.id = ? and `+visibility, append([]interface{}{postID}, arguments...)...)
 	if error != nil {
 		return models.Post{}, error
 	}
 	defer lines.Close()
 	var post models.Post
 	if lines.Next() {
-		if error = lines.Scan(&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Likes, &post.CreatedAt, &post.AuthorNick); error != nil {
+		if error = scanPost(lines, &post); error != nil {
 			return models.Post{}, error
 		}
 	}
 	return post, nil
 }
 
-//Fetch fetches all posts from followed users
-func (repository Posts) Fetch(userID uint64) ([]models.Post, error) {
-	lines, error := repository.db.Query(`select p.*, u.nick from posts p inner 
-	join users u on u.id = p.author_id inner 
-	join followers s on p.author_id = s.user_id 
-	where u.id = ? or s.follower_id = ? order by 1 desc`, userID, userID)
+//FetchShareable fetches a post the viewer is allowed to repost or quote
+func (repository Posts) FetchShareable(postID, viewerID uint64) (models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p
+	inner join users u on u.id = p.author_id
+	where p.id = ? and `+shareable+` and `+visibility, append([]interface{}{postID}, arguments...)...)
+	if error != nil {
+		return models.Post{}, error
+	}
+	defer lines.Close()
+	var post models.Post
+	if lines.Next() {
+		if error = scanPost(lines, &post); error != nil {
+			return models.Post{}, error
+		}
+	}
+	return post, nil
+}
+
+//Fetch fetches the timeline of the user: their own posts, the posts from followed users, the public posts
+//with followed tags and the posts reposted by the user or followed users. A post appears only once, as
+//an original when it is part of the timeline by itself or else reposted by its latest reposter
+func (repository Posts) Fetch(userID, limit, offset uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(userID)
+	lines, error := repository.db.Query(`select `+postColumns+`, coalesce(t.reposter_id, 0), coalesce(ru.nick, '')
+	from (
+		select e.post_id, e.reposter_id, e.activityAt,
+		row_number() over (partition by e.post_id order by e.reposter_id is null desc, e.activityAt desc) position
+		from (
+			select p.id post_id, null reposter_id, p.createdAt activityAt from posts p
+			where p.author_id = ?
+			or p.author_id in (select s.user_id from followers s where s.follower_id = ?)
+			or (p.visibility = 'public' and p.id in (select pt.post_id from post_tags pt inner join tag_followers tf on tf.tag = pt.tag where tf.user_id = ?))
+			union all
+			select r.post_id, r.user_id, r.createdAt from reposts r
+			where (r.user_id = ? or r.user_id in (select s.user_id from followers s where s.follower_id = ?))
+			and `+notMutedBy("r.user_id")+`
+		) e
+	) t
+	inner join posts p on p.id = t.post_id
+	inner join users u on u.id = p.author_id
+	left join users ru on ru.id = t.reposter_id
+	where t.position = 1
+	and `+notMutedBy("p.author_id")+`
+	and `+visibility+`
+	order by t.activityAt desc, p.id desc limit ? offset ?`,
+		append(append([]interface{}{userID, userID, userID, userID, userID, userID, userID}, arguments...), limit, offset)...,
+	)
 	if error != nil {
 		return nil, error
 	}
 	defer lines.Close()
-	var posts []models.Post
 
+	var posts []models.Post
 	for lines.Next() {
 		var post models.Post
-		if error = lines.Scan(&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Likes, &post.CreatedAt, &post.AuthorNick); error != nil {
+		if error = scanPost(lines, &post, &post.RepostedByID, &post.RepostedByNick); error != nil {
 			return nil, error
 		}
 		posts = append(posts, post)
@@ -71,44 +169,98 @@ func (repository Posts) Fetch(userID uint64) ([]models.Post, error) {
 	return posts, nil
 }
 
-//Update the post
+//FetchExplore fetches the public posts published since the time, the highest scored first. Replies and
+//the posts of users blocked or muted by the viewer are left out
+func (repository Posts) FetchExplore(viewerID uint64, since time.Time, limit, offset uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p
+	inner join users u on u.id = p.author_id
+	where p.createdAt >= ? and p.visibility = 'public' and p.parent_id is null
+	and `+notMutedBy("p.author_id")+` and `+visibility+`
+	order by p.score desc, p.id desc limit ? offset ?`,
+		append(append([]interface{}{since, viewerID}, arguments...), limit, offset)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanPosts(lines)
+}
+
+//FetchByTag fetches the public posts with a tag the viewer is allowed to see
+func (repository Posts) FetchByTag(tag string, viewerID, limit, offset uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p
+	inner join users u on u.id = p.author_id
+	inner join post_tags pt on pt.post_id = p.id
+	where pt.tag = ? and p.visibility = 'public' and `+visibility+`
+	order by p.id desc limit ? offset ?`,
+		append(append([]interface{}{tag}, arguments...), limit, offset)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanPosts(lines)
+}
+
+//Update the post and, when it is published, its tags
 func (repository Posts) Update(postID uint64, post models.Post) error {
-	statement, error := repository.db.Prepare(`update posts set title = ?, content = ? where id = ?`)
+	transaction, error := repository.db.Begin()
 	if error != nil {
 		return error
 	}
-	defer statement.Close()
-	if _, error = statement.Exec(post.Title, post.Content, postID); error != nil {
+	defer transaction.Rollback()
+
+	if _, error = transaction.Exec(`update posts set title = ?, content = ?, visibility = ? where id = ?`, post.Title, post.Content, post.Visibility, postID); error != nil {
 		return error
 	}
-	return nil
+	if _, error = transaction.Exec(`delete from post_tags where post_id = ?`, postID); error != nil {
+		return error
+	}
+	if error = saveTags(transaction, postID, post.Tags); error != nil {
+		return error
+	}
+	return transaction.Commit()
 }
 
-//Delete the post
+//Delete the post, removing it from the counts of the posts it quotes and replies to if it was published.
+//Its replies are kept without a parent
 func (repository Posts) Delete(postID uint64) error {
-	statement, error := repository.db.Prepare(`delete from posts where id = ?`)
+	transaction, error := repository.db.Begin()
 	if error != nil {
 		return error
 	}
-	defer statement.Close()
-	if _, error = statement.Exec(postID); error != nil {
+	defer transaction.Rollback()
+
+	if error = uncountReferences(transaction, postID); error != nil {
 		return error
 	}
-	return nil
+	if _, error = transaction.Exec(`delete from posts where id = ?`, postID); error != nil {
+		return error
+	}
+	return transaction.Commit()
 }
 
-//FetchPostByUser fetches all posts from a user
-func (repository Posts) FetchPostByUser(userID uint64) ([]models.Post, error) {
-	lines, error := repository.db.Query(`select p.*, u.nick from posts p join users u on u.id = p.author_id where p.author_id = ?`, userID)
+//FetchPostByUser fetches the posts from a user the viewer is allowed to see, the pinned ones first in their order
+func (repository Posts) FetchPostByUser(userID, viewerID uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+postColumns+`, pp.post_id is not null from posts p
+	join users u on u.id = p.author_id
+	left join pinned_posts pp on pp.user_id = p.author_id and pp.post_id = p.id
+	where p.author_id = ? and `+visibility+`
+	order by pp.position is null, pp.position, p.id desc`, append([]interface{}{userID}, arguments...)...)
 	if error != nil {
 		return nil, error
 	}
 	defer lines.Close()
-	var posts []models.Post
 
+	var posts []models.Post
 	for lines.Next() {
 		var post models.Post
-		if error = lines.Scan(&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Likes, &post.CreatedAt, &post.AuthorNick); error != nil {
+		if error = scanPost(lines, &post, &post.Pinned); error != nil {
 			return nil, error
 		}
 		posts = append(posts, post)
@@ -116,27 +268,510 @@ func (repository Posts) FetchPostByUser(userID uint64) ([]models.Post, error) {
 	return posts, nil
 }
 
-//Like the post
-func (repository Posts) Like(postID uint64) error {
-	statement, error := repository.db.Prepare(`update posts set likes = likes + 1 where id = ?`)
+//FetchByStatus fetches the drafts or the scheduled posts of the author, the scheduled ones by publication time
+func (repository Posts) FetchByStatus(authorID uint64, status string, limit, offset uint64) ([]models.Post, error) {
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p
+	inner join users u on u.id = p.author_id
+	where p.author_id = ? and p.status = ?
+	order by p.publish_at, p.id desc limit ? offset ?`, authorID, status, limit, offset)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanPosts(lines)
+}
+
+//FetchDueIDs fetches the IDs of the scheduled posts whose publication time has come, the oldest first, leaving out the excluded ones
+func (repository Posts) FetchDueIDs(limit uint64, excludedIDs []uint64) ([]uint64, error) {
+	exclusion := ""
+	arguments := make([]interface{}, 0, len(excludedIDs)+1)
+	if len(excludedIDs) > 0 {
+		exclusion = " and id not in (" + placeholders(len(excludedIDs)) + ")"
+		for _, postID := range excludedIDs {
+			arguments = append(arguments, postID)
+		}
+	}
+	lines, error := repository.db.Query(`select id from posts where status = 'scheduled' and publish_at <= now()`+exclusion+`
+	order by publish_at limit ?`, append(arguments, limit)...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanIDs(lines)
+}
+
+//Schedule sets the publication time of a draft or scheduled post
+func (repository Posts) Schedule(postID uint64, publishAt time.Time) error {
+	statement, error := repository.db.Prepare(`update posts set status = 'scheduled', publish_at = ? where id = ? and status in ('draft', 'scheduled')`)
 	if error != nil {
 		return error
 	}
 	defer statement.Close()
-	if _, error = statement.Exec(postID); error != nil {
+	if _, error = statement.Exec(publishAt, postID); error != nil {
 		return error
 	}
 	return nil
 }
 
-//Dislike removes the like
-func (repository Posts) Dislike(postID uint64) error {
-	statement, error := repository.db.Prepare(`update posts set likes = CASE WHEN likes > 0 THEN likes - 1 ELSE likes END where id = ?`)
+//Unschedule turns a scheduled post back into a draft
+func (repository Posts) Unschedule(postID uint64) error {
+	statement, error := repository.db.Prepare(`update posts set status = 'draft', publish_at = null where id = ? and status = 'scheduled'`)
 	if error != nil {
 		return error
 	}
+	defer statement.Close()
 	if _, error = statement.Exec(postID); error != nil {
 		return error
 	}
 	return nil
 }
+
+//Publish publishes a draft or scheduled post now, saving its tags and counting it in the posts it quotes and
+//replies to. It returns false when the post was already published, so concurrent publications only happen once
+func (repository Posts) Publish(postID uint64) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	var content string
+	if error = transaction.QueryRow(`select content from posts where id = ? and status <> 'published' for update`, postID).Scan(&content); error != nil {
+		if error == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, error
+	}
+	if error = publish(transaction, postID, content); error != nil {
+		return false, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//Release ends the hold of a post and returns the status it goes back to, empty when the post is not held.
+//Drafts and scheduled posts keep waiting for their author or their time, the others are published
+func (repository Posts) Release(postID uint64) (string, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return "", error
+	}
+	defer transaction.Rollback()
+
+	var content string
+	var heldStatus sql.NullString
+	if error = transaction.QueryRow(`select content, held_status from posts where id = ? and status = 'held' for update`, postID).Scan(&content, &heldStatus); error != nil {
+		if error == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", error
+	}
+	status := heldStatus.String
+	switch status {
+	case models.StatusDraft, models.StatusScheduled:
+		if _, error = transaction.Exec(`update posts set status = held_status, held_status = null where id = ?`, postID); error != nil {
+			return "", error
+		}
+	default:
+		status = models.StatusPublished
+		if error = publish(transaction, postID, content); error != nil {
+			return "", error
+		}
+	}
+	if error = transaction.Commit(); error != nil {
+		return "", error
+	}
+	return status, nil
+}
+
+//RecentContents fetches the content of the posts the author created since the time, leaving out a post being edited
+func (repository Posts) RecentContents(authorID, exceptPostID uint64, since time.Time) ([]string, error) {
+	lines, error := repository.db.Query(`select content from posts where author_id = ? and id <> ? and createdAt >= ?`, authorID, exceptPostID, since)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var contents []string
+	for lines.Next() {
+		var content string
+		if error = lines.Scan(&content); error != nil {
+			return nil, error
+		}
+		contents = append(contents, content)
+	}
+	return contents, nil
+}
+
+//CountSince counts the posts the author created since the time
+func (repository Posts) CountSince(authorID uint64, since time.Time) (uint64, error) {
+	var count uint64
+	if error := repository.db.QueryRow(`select count(*) from posts where author_id = ? and createdAt >= ?`, authorID, since).Scan(&count); error != nil {
+		return 0, error
+	}
+	return count, nil
+}
+
+//Hold keeps the post away from readers until a moderator reviews it, remembering its status for Release,
+//and opens the report the moderators review with the reason. A published post leaves the counts of the posts
+//it quotes and replies to and loses its tags, they come back when it is published again
+func (repository Posts) Hold(postID uint64, reason string) error {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return error
+	}
+	defer transaction.Rollback()
+
+	if error = uncountReferences(transaction, postID); error != nil {
+		return error
+	}
+	if _, error = transaction.Exec(`delete from post_tags where post_id = ?`, postID); error != nil {
+		return error
+	}
+	if _, error = transaction.Exec(`update posts set held_status = status, status = 'held' where id = ? and status <> 'held'`, postID); error != nil {
+		return error
+	}
+	if error = reportHeldPost(transaction, postID, reason); error != nil {
+		return error
+	}
+	return transaction.Commit()
+}
+
+//Like records the like of the user on the post and counts it in the stats of the day. It returns whether the like
+//is new, liking twice has no effect, and whether it is the first time the user likes the post: only that one raises
+//its score, so liking again after a dislike can't push the post up the explore feed
+func (repository Posts) Like(postID, userID uint64) (bool, bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, false, error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec(`insert ignore into post_likes (user_id, post_id) values (?, ?)`, userID, postID)
+	if error != nil {
+		return false, false, error
+	}
+	rowsAffected, error := result.RowsAffected()
+	if error != nil || rowsAffected == 0 {
+		return false, false, error
+	}
+	if _, error = transaction.Exec(`update posts set likes = likes + 1 where id = ?`, postID); error != nil {
+		return false, false, error
+	}
+	if error = countLike(transaction, postID); error != nil {
+		return false, false, error
+	}
+	result, error = transaction.Exec(`insert ignore into scored_likes (user_id, post_id) values (?, ?)`, userID, postID)
+	if error != nil {
+		return false, false, error
+	}
+	if rowsAffected, error = result.RowsAffected(); error != nil {
+		return false, false, error
+	}
+	first := rowsAffected > 0
+	if first {
+		score := ranking.Score(ranking.Like, time.Now())
+		if _, error = transaction.Exec(`update posts set score = `+ranking.Add("score")+` where id = ?`, score, score, postID); error != nil {
+			return false, false, error
+		}
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, false, error
+	}
+	return true, first, nil
+}
+
+//Dislike removes the like of the user and takes it back from the stats of the day, the score it added is kept
+//and fades with time. It returns false when the user didn't like the post
+func (repository Posts) Dislike(postID, userID uint64) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec(`delete from post_likes where user_id = ? and post_id = ?`, userID, postID)
+	if error != nil {
+		return false, error
+	}
+	rowsAffected, error := result.RowsAffected()
+	if error != nil || rowsAffected == 0 {
+		return false, error
+	}
+	if _, error = transaction.Exec(`update posts set likes = likes - 1 where id = ? and likes > 0`, postID); error != nil {
+		return false, error
+	}
+	if error = uncountLike(transaction, postID); error != nil {
+		return false, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//FetchAncestors fetches the posts above the post in its thread that the viewer is allowed to see, the root first
+func (repository Posts) FetchAncestors(postID, viewerID uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`with recursive ancestors (id, parent_id, depth) as (
+		select id, parent_id, 0 from posts where id = ?
+		union all
+		select parent.id, parent.parent_id, a.depth + 1 from posts parent
+		inner join ancestors a on parent.id = a.parent_id
+		where a.depth < ?
+	)
+	select `+postColumns+` from ancestors a
+	inner join posts p on p.id = a.id
+	inner join users u on u.id = p.author_id
+	where a.depth > 0 and `+visibility+`
+	order by a.depth desc`, append([]interface{}{postID, maxThreadDepth}, arguments...)...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanPosts(lines)
+}
+
+//FetchReplies fetches a page of the direct replies to the post, oldest first, each one with its own replies
+//nested up to the maximum thread depth. Replies the viewer is not allowed to see hide their descendants
+func (repository Posts) FetchReplies(postID, viewerID, limit, offset uint64) ([]models.Post, error) {
+	visibility, arguments := visibleTo(viewerID)
+	lines, error := repository.db.Query(`select `+postColumns+` from posts p
+	inner join users u on u.id = p.author_id
+	where p.parent_id = ? and `+visibility+`
+	order by p.id limit ? offset ?`, append(append([]interface{}{postID}, arguments...), limit, offset)...)
+	if error != nil {
+		return nil, error
+	}
+	replies, error := scanPosts(lines)
+	lines.Close()
+	if error != nil || len(replies) == 0 {
+		return replies, error
+	}
+
+	rootIDs := make([]interface{}, len(replies))
+	for i, reply := range replies {
+		rootIDs[i] = reply.ID
+	}
+	lines, error = repository.db.Query(`with recursive descendants (id, depth) as (
+		select id, 1 from posts where parent_id in (`+placeholders(len(rootIDs))+`)
+		union all
+		select child.id, d.depth + 1 from posts child
+		inner join descendants d on child.parent_id = d.id
+		where d.depth < ?
+	)
+	select `+postColumns+` from descendants d
+	inner join posts p on p.id = d.id
+	inner join users u on u.id = p.author_id
+	where `+visibility+`
+	order by p.id`, append(append(rootIDs, maxThreadDepth), arguments...)...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+	descendants, error := scanPosts(lines)
+	if error != nil {
+		return nil, error
+	}
+
+	children := map[uint64][]models.Post{}
+	for _, descendant := range descendants {
+		children[descendant.ParentID] = append(children[descendant.ParentID], descendant)
+	}
+	for i := range replies {
+		replies[i].Children = nestReplies(replies[i].ID, children)
+	}
+	return replies, nil
+}
+
+//Repost shares the post with the followers of the user. Reposting twice has no effect, and only the first repost
+//of the post by the user raises its score, so reposting it again after an unrepost can't push it up the explore feed
+func (repository Posts) Repost(postID, userID uint64) error {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec(`insert ignore into reposts (user_id, post_id) values (?, ?)`, userID, postID)
+	if error != nil {
+		return error
+	}
+	rowsAffected, error := result.RowsAffected()
+	if error != nil {
+		return error
+	}
+	if rowsAffected == 0 {
+		return nil
+	}
+	if _, error = transaction.Exec(`update posts set reposts = reposts + 1 where id = ?`, postID); error != nil {
+		return error
+	}
+	result, error = transaction.Exec(`insert ignore into scored_reposts (user_id, post_id) values (?, ?)`, userID, postID)
+	if error != nil {
+		return error
+	}
+	if rowsAffected, error = result.RowsAffected(); error != nil {
+		return error
+	}
+	if rowsAffected > 0 {
+		score := ranking.Score(ranking.Repost, time.Now())
+		if _, error = transaction.Exec(`update posts set score = `+ranking.Add("score")+` where id = ?`, score, score, postID); error != nil {
+			return error
+		}
+	}
+	return transaction.Commit()
+}
+
+//Unrepost undoes the repost of the post by the user, the score it brought is kept as it is only given once
+func (repository Posts) Unrepost(postID, userID uint64) error {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec(`delete from reposts where user_id = ? and post_id = ?`, userID, postID)
+	if error != nil {
+		return error
+	}
+	rowsAffected, error := result.RowsAffected()
+	if error != nil {
+		return error
+	}
+	if rowsAffected > 0 {
+		if _, error = transaction.Exec(`update posts set reposts = CASE WHEN reposts > 0 THEN reposts - 1 ELSE reposts END where id = ?`, postID); error != nil {
+			return error
+		}
+	}
+	return transaction.Commit()
+}
+
+//nestReplies builds the tree of replies below the post
+func nestReplies(postID uint64, children map[uint64][]models.Post) []models.Post {
+	replies := children[postID]
+	for i := range replies {
+		replies[i].Children = nestReplies(replies[i].ID, children)
+	}
+	return replies
+}
+
+// inCirculation is the condition that leaves out the posts seen by no one: unpublished posts, posts hidden
+// by the moderators and posts of suspended users. It expects the posts as p and their authors as u
+const inCirculation = `p.status = 'published' and p.hidden = false and u.suspended = false`
+
+//visibleTo is the condition every read path applies to hide the posts the viewer is not allowed
+//to see, and its arguments. It expects the posts as p and their authors as u. Posts out of circulation
+//are seen by no one
+func visibleTo(viewerID uint64) (string, []interface{}) {
+	return `(` + inCirculation + ` and ` + notBlockedWith("p.author_id") + `
+	and (p.author_id = ? or (u.private = false and p.visibility <> 'followers')
+		or exists (select 1 from followers vf where vf.user_id = p.author_id and vf.follower_id = ?)))`,
+		[]interface{}{viewerID, viewerID, viewerID, viewerID}
+}
+
+//reportHeldPost opens a report without reporter about a post held by the content policy, unless one is still open
+func reportHeldPost(transaction *sql.Tx, postID uint64, reason string) error {
+	_, error := transaction.Exec(`insert into reports (reporter_id, user_id, post_id, reason, details)
+	select null, p.author_id, p.id, ?, ? from posts p
+	where p.id = ? and not exists (select 1 from reports r where r.reporter_id is null and r.post_id = p.id and r.status <> 'resolved')`,
+		models.ReasonPolicy, reason, postID,
+	)
+	return error
+}
+
+//publish makes the post visible from now on, counting it in the posts it references and linking its tags
+func publish(transaction *sql.Tx, postID uint64, content string) error {
+	if _, error := transaction.Exec(`update posts set status = 'published', publish_at = null, held_status = null, createdAt = now(), score = ? where id = ?`,
+		ranking.Score(ranking.Publication, time.Now()), postID,
+	); error != nil {
+		return error
+	}
+	if error := countReferences(transaction, postID); error != nil {
+		return error
+	}
+	return saveTags(transaction, postID, models.ParseTags(content))
+}
+
+//countReferences counts the post in the post it quotes and in the post it replies to, raising their scores
+func countReferences(transaction *sql.Tx, postID uint64) error {
+	repostScore := ranking.Score(ranking.Repost, time.Now())
+	if _, error := transaction.Exec(`update posts original inner join posts quote on quote.quote_of_id = original.id
+	set original.quotes = original.quotes + 1, original.score = `+ranking.Add("original.score")+` where quote.id = ?`,
+		repostScore, repostScore, postID,
+	); error != nil {
+		return error
+	}
+	replyScore := ranking.Score(ranking.Reply, time.Now())
+	if _, error := transaction.Exec(`update posts parent inner join posts reply on reply.parent_id = parent.id
+	set parent.replies = parent.replies + 1, parent.score = `+ranking.Add("parent.score")+` where reply.id = ?`,
+		replyScore, replyScore, postID,
+	); error != nil {
+		return error
+	}
+	return nil
+}
+
+//uncountReferences removes the post from the counts of the post it quotes and of the post it replies to, if it is published
+func uncountReferences(transaction *sql.Tx, postID uint64) error {
+	if _, error := transaction.Exec(`update posts original inner join posts quote on quote.quote_of_id = original.id
+	set original.quotes = CASE WHEN original.quotes > 0 THEN original.quotes - 1 ELSE 0 END
+	where quote.id = ? and quote.status = 'published'`, postID); error != nil {
+		return error
+	}
+	if _, error := transaction.Exec(`update posts parent inner join posts reply on reply.parent_id = parent.id
+	set parent.replies = CASE WHEN parent.replies > 0 THEN parent.replies - 1 ELSE 0 END
+	where reply.id = ? and reply.status = 'published'`, postID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//saveTags links the tags to the post if it is published, they keep the creation date of the post so editing it doesn't make them trend
+func saveTags(transaction *sql.Tx, postID uint64, tags []string) error {
+	for _, tag := range tags {
+		if _, error := transaction.Exec(`insert ignore into post_tags (post_id, tag, createdAt) select id, ?, createdAt from posts where id = ? and status = 'published'`, tag, postID); error != nil {
+			return error
+		}
+	}
+	return nil
+}
+
+//scanPost reads a line selected with the post columns, followed by the extra columns
+func scanPost(lines *sql.Rows, post *models.Post, extra ...interface{}) error {
+	return lines.Scan(append([]interface{}{
+		&post.ID,
+		&post.Title,
+		&post.Content,
+		&post.AuthorID,
+		&post.Visibility,
+		&post.Status,
+		&post.PublishAt,
+		&post.QuoteOfID,
+		&post.ParentID,
+		&post.Likes,
+		&post.Reposts,
+		&post.Quotes,
+		&post.Replies,
+		&post.CreatedAt,
+		&post.AuthorNick,
+	}, extra...)...)
+}
+
+//scanPosts reads all the lines selected with the post columns
+func scanPosts(lines *sql.Rows) ([]models.Post, error) {
+	var posts []models.Post
+	for lines.Next() {
+		var post models.Post
+		if error := scanPost(lines, &post); error != nil {
+			return nil, error
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeLikes is a database/sql driver standing in for MySQL in the like tests. It understands only the
+// statements run by Like and Dislike, keeping the rows of the like tables and the likes and score of the posts
+type fakeLikes struct {
+	mutex  sync.Mutex
+	rows   map[string]bool
+	likes  map[string]int64
+	scores map[string]float64
+}
+
+var likesDatabase = &fakeLikes{}
+
+func init() {
+	sql.Register("fakelikes", likesDatabase)
+}
+
+func (fake *fakeLikes) reset() {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.rows = map[string]bool{}
+	fake.likes = map[string]int64{}
+	fake.scores = map[string]float64{}
+}
+
+func (fake *fakeLikes) Open(string) (driver.Conn, error) { return fake, nil }
+func (fake *fakeLikes) Close() error                     { return nil }
+func (fake *fakeLikes) Begin() (driver.Tx, error)        { return fake, nil }
+func (fake *fakeLikes) Commit() error                    { return nil }
+func (fake *fakeLikes) Rollback() error                  { return nil }
+
+func (fake *fakeLikes) Prepare(query string) (driver.Stmt, error) {
+	return fakeStatement{fake, strings.Join(strings.Fields(query), " ")}, nil
+}
+
+type fakeStatement struct {
+	fake  *fakeLikes
+	query string
+}
+
+func (statement fakeStatement) Close() error  { return nil }
+func (statement fakeStatement) NumInput() int { return -1 }
+
+func (statement fakeStatement) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries are not supported")
+}
+
+func (statement fakeStatement) Exec(arguments []driver.Value) (driver.Result, error) {
+	fake := statement.fake
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	query := statement.query
+	switch {
+	case strings.HasPrefix(query, "insert ignore into post_likes"), strings.HasPrefix(query, "insert ignore into scored_likes"):
+		key := fmt.Sprint(strings.Fields(query)[3], arguments)
+		if fake.rows[key] {
+			return driver.RowsAffected(0), nil
+		}
+		fake.rows[key] = true
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "delete from post_likes"):
+		key := fmt.Sprint("post_likes", arguments)
+		if !fake.rows[key] {
+			return driver.RowsAffected(0), nil
+		}
+		delete(fake.rows, key)
+		return driver.RowsAffected(1), nil
+	case query == "update posts set likes = likes + 1 where id = ?":
+		fake.likes[fmt.Sprint(arguments[0])]++
+		return driver.RowsAffected(1), nil
+	case query == "update posts set likes = likes - 1 where id = ? and likes > 0":
+		postID := fmt.Sprint(arguments[0])
+		if fake.likes[postID] == 0 {
+			return driver.RowsAffected(0), nil
+		}
+		fake.likes[postID]--
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "update posts set score = greatest(score, ?)"):
+		postID := fmt.Sprint(arguments[2])
+		score := arguments[0].(float64)
+		if current, found := fake.scores[postID]; found {
+			score = math.Max(current, score) + math.Log2(1+math.Pow(2, -math.Abs(current-score)))
+		}
+		fake.scores[postID] = score
+		return driver.RowsAffected(1), nil
+	case strings.Contains(query, "post_daily_stats"), strings.Contains(query, "author_daily_stats"):
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+func newLikesRepository(t *testing.T) *Posts {
+	t.Helper()
+	likesDatabase.reset()
+	db, error := sql.Open("fakelikes", "")
+	if error != nil {
+		t.Fatalf("opening the fake database: %v", error)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewPostRepository(db)
+}
+
+func like(t *testing.T, repository *Posts, postID, userID uint64) (bool, bool) {
+	t.Helper()
+	liked, first, error := repository.Like(postID, userID)
+	if error != nil {
+		t.Fatalf("Like: %v", error)
+	}
+	return liked, first
+}
+
+func dislike(t *testing.T, repository *Posts, postID, userID uint64) bool {
+	t.Helper()
+	disliked, error := repository.Dislike(postID, userID)
+	if error != nil {
+		t.Fatalf("Dislike: %v", error)
+	}
+	return disliked
+}
+
+func TestLikeRaisesTheScoreOncePerUser(t *testing.T) {
+	repository := newLikesRepository(t)
+
+	if liked, first := like(t, repository, 1, 10); !liked || !first {
+		t.Fatalf("the first like returned %v, %v, want true, true", liked, first)
+	}
+	score := likesDatabase.scores["1"]
+
+	if liked, first := like(t, repository, 1, 10); liked || first {
+		t.Errorf("a second like returned %v, %v, want false, false", liked, first)
+	}
+	if likesDatabase.scores["1"] != score || likesDatabase.likes["1"] != 1 {
+		t.Errorf("a second like changed the post to %d likes with score %v, want 1 like with score %v",
+			likesDatabase.likes["1"], likesDatabase.scores["1"], score)
+	}
+
+	for i := 0; i < 3; i++ {
+		if !dislike(t, repository, 1, 10) {
+			t.Fatalf("the dislike of a liked post returned false")
+		}
+		if liked, first := like(t, repository, 1, 10); !liked || first {
+			t.Errorf("liking again after a dislike returned %v, %v, want true, false", liked, first)
+		}
+	}
+	if likesDatabase.scores["1"] != score || likesDatabase.likes["1"] != 1 {
+		t.Errorf("like and dislike cycles changed the post to %d likes with score %v, want 1 like with score %v",
+			likesDatabase.likes["1"], likesDatabase.scores["1"], score)
+	}
+
+	if liked, first := like(t, repository, 1, 11); !liked || !first {
+		t.Fatalf("the like of another user returned %v, %v, want true, true", liked, first)
+	}
+	if likesDatabase.scores["1"] <= score || likesDatabase.likes["1"] != 2 {
+		t.Errorf("the like of another user left the post with %d likes and score %v, want 2 likes and a score above %v",
+			likesDatabase.likes["1"], likesDatabase.scores["1"], score)
+	}
+}
+
+func TestDislikeWithoutLike(t *testing.T) {
+	repository := newLikesRepository(t)
+	like(t, repository, 1, 10)
+
+	if dislike(t, repository, 1, 11) {
+		t.Errorf("the dislike of a user who didn't like the post returned true")
+	}
+	if likesDatabase.likes["1"] != 1 {
+		t.Errorf("the post has %d likes, want 1", likesDatabase.likes["1"])
+	}
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// LinkPreviews represents a link preview repository
+type LinkPreviews struct {
+	db *sql.DB
+}
+
+//NewLinkPreviewRepository creates a link preview repository
+func NewLinkPreviewRepository(db *sql.DB) *LinkPreviews {
+	return &LinkPreviews{db}
+}
+
+//Link replaces the links of the post, reusing the previews already cached.
+//It returns the links whose preview still has to be fetched
+func (repository LinkPreviews) Link(postID uint64, links []string) ([]string, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return nil, error
+	}
+	defer transaction.Rollback()
+
+	if _, error = transaction.Exec("delete from post_links where post_id = ?", postID); error != nil {
+		return nil, error
+	}
+
+	var pending []string
+	for position, link := range links {
+		result, error := transaction.Exec(`insert into link_previews (url, url_hash) values (?, sha2(?, 256))
+		on duplicate key update id = last_insert_id(id)`, link, link)
+		if error != nil {
+			return nil, error
+		}
+		previewID, error := result.LastInsertId()
+		if error != nil {
+			return nil, error
+		}
+		if _, error = transaction.Exec("insert into post_links (post_id, preview_id, position) values (?, ?, ?)", postID, previewID, position); error != nil {
+			return nil, error
+		}
+
+		var status string
+		if error = transaction.QueryRow("select status from link_previews where id = ?", previewID).Scan(&status); error != nil {
+			return nil, error
+		}
+		if status == "pending" {
+			pending = append(pending, link)
+		}
+	}
+	if error = transaction.Commit(); error != nil {
+		return nil, error
+	}
+	return pending, nil
+}
+
+//Save stores the fetched preview of the link
+func (repository LinkPreviews) Save(preview models.LinkPreview) error {
+	statement, error := repository.db.Prepare(`update link_previews set title = ?, description = ?, image = ?, status = 'ready', fetchedAt = now()
+	where url_hash = sha2(?, 256)`)
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+
+	if _, error = statement.Exec(preview.Title, preview.Description, preview.Image, preview.URL); error != nil {
+		return error
+	}
+	return nil
+}
+
+//MarkFailed records that the link has no preview, so it is not fetched again
+func (repository LinkPreviews) MarkFailed(link string) error {
+	statement, error := repository.db.Prepare("update link_previews set status = 'failed', fetchedAt = now() where url_hash = sha2(?, 256)")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+
+	if _, error = statement.Exec(link); error != nil {
+		return error
+	}
+	return nil
+}
+
+//FetchPending fetches the links whose preview was never fetched, the oldest first
+func (repository LinkPreviews) FetchPending(limit uint64) ([]string, error) {
+	lines, error := repository.db.Query("select url from link_previews where status = 'pending' order by id limit ?", limit)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var links []string
+	for lines.Next() {
+		var link string
+		if error = lines.Scan(&link); error != nil {
+			return nil, error
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+//FetchByPosts fetches the ready previews of the links of the posts, grouped by post in the order the links appear
+func (repository LinkPreviews) FetchByPosts(postIDs []uint64) (map[uint64][]models.LinkPreview, error) {
+	previewsByPost := map[uint64][]models.LinkPreview{}
+	if len(postIDs) == 0 {
+		return previewsByPost, nil
+	}
+	arguments := make([]interface{}, len(postIDs))
+	for i, postID := range postIDs {
+		arguments[i] = postID
+	}
+	lines, error := repository.db.Query(`select pl.post_id, lp.url, lp.title, lp.description, lp.image
+	from post_links pl inner join link_previews lp on lp.id = pl.preview_id
+	where pl.post_id in (`+placeholders(len(postIDs))+`) and lp.status = 'ready'
+	order by pl.post_id, pl.position`, arguments...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	for lines.Next() {
+		var postID uint64
+		var preview models.LinkPreview
+		if error = lines.Scan(&postID, &preview.URL, &preview.Title, &preview.Description, &preview.Image); error != nil {
+			return nil, error
+		}
+		previewsByPost[postID] = append(previewsByPost[postID], preview)
+	}
+	return previewsByPost, nil
+}
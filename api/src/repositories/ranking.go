@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"api/src/ranking"
+	"database/sql"
+	"time"
+)
+
+// Ranking represents the repository of the scores of the explore feed
+type Ranking struct {
+	db *sql.DB
+}
+
+//NewRankingRepository creates a ranking repository
+func NewRankingRepository(db *sql.DB) *Ranking {
+	return &Ranking{db}
+}
+
+//ScoredWith returns whether the stored scores were computed with the half life
+func (repository Ranking) ScoredWith(halfLife time.Duration) (bool, error) {
+	var halfLifeHours float64
+	error := repository.db.QueryRow(`select half_life_hours from ranking`).Scan(&halfLifeHours)
+	if error == sql.ErrNoRows {
+		return false, nil
+	}
+	if error != nil {
+		return false, error
+	}
+	return halfLifeHours == halfLife.Hours(), nil
+}
+
+//Rescore computes again the score of every post with the current half life, from its publication and the engagements
+//that raised it: the first like and repost of each user, and the published quotes and replies. It returns how many
+//posts changed score. The terms are added relative to the highest one of each post so they don't overflow
+func (repository Ranking) Rescore() (int64, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return 0, error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec(`update posts p inner join (
+		select post_id, max(top) + log2(sum(pow(2, term - top))) score from (
+			select post_id, term, max(term) over (partition by post_id) top from (
+				select id post_id, ` + ranking.Expression(ranking.Publication, "createdAt") + ` term from posts
+				union all select post_id, ` + ranking.Expression(ranking.Like, "createdAt") + ` from scored_likes
+				union all select post_id, ` + ranking.Expression(ranking.Repost, "createdAt") + ` from scored_reposts
+				union all select quote_of_id, ` + ranking.Expression(ranking.Repost, "createdAt") + ` from posts
+				where quote_of_id is not null and status = 'published'
+				union all select parent_id, ` + ranking.Expression(ranking.Reply, "createdAt") + ` from posts
+				where parent_id is not null and status = 'published'
+			) events
+		) terms group by post_id
+	) scores on scores.post_id = p.id
+	set p.score = scores.score`)
+	if error != nil {
+		return 0, error
+	}
+	rescored, error := result.RowsAffected()
+	if error != nil {
+		return 0, error
+	}
+	if _, error = transaction.Exec(`delete from ranking`); error != nil {
+		return 0, error
+	}
+	if _, error = transaction.Exec(`insert into ranking (half_life_hours) values (?)`, ranking.HalfLife.Hours()); error != nil {
+		return 0, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return 0, error
+	}
+	return rescored, nil
+}
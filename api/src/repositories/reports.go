@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// reportColumns are the columns selected when reading reports, r is the report, ru the reporter, u the reported user and p the post
+const reportColumns = `r.id, coalesce(r.reporter_id, 0), coalesce(ru.nick, ''), r.user_id, u.nick, coalesce(r.post_id, 0), coalesce(p.content, ''),
+	r.reason, r.details, r.status, coalesce(r.moderator_id, 0), coalesce(r.action, ''), r.claimedAt, r.resolvedAt, r.createdAt`
+
+// reportJoins are the tables joined to read reports
+const reportJoins = `from reports r
+	left join users ru on ru.id = r.reporter_id
+	inner join users u on u.id = r.user_id
+	left join posts p on p.id = r.post_id`
+
+// Reports represents a report repository
+type Reports struct {
+	db *sql.DB
+}
+
+//NewReportRepository creates a report repository
+func NewReportRepository(db *sql.DB) *Reports {
+	return &Reports{db}
+}
+
+//Create inserts the report, unless the reporter already has an unresolved report about the same post
+//or user. Reports without a reporter are created by the content policy. It returns the ID of the report
+func (repository Reports) Create(report models.Report) (uint64, error) {
+	var reportID uint64
+	error := repository.db.QueryRow(`select id from reports
+	where reporter_id <=> ? and user_id = ? and post_id <=> ? and status <> 'resolved'`,
+		nullableID(report.ReporterID), report.UserID, nullableID(report.PostID),
+	).Scan(&reportID)
+	if error == nil {
+		return reportID, nil
+	}
+	if error != sql.ErrNoRows {
+		return 0, error
+	}
+
+	result, error := repository.db.Exec(`insert into reports (reporter_id, user_id, post_id, reason, details) values (?, ?, ?, ?, ?)`,
+		nullableID(report.ReporterID), report.UserID, nullableID(report.PostID), report.Reason, report.Details,
+	)
+	if error != nil {
+		return 0, error
+	}
+	lastInsertID, error := result.LastInsertId()
+	if error != nil {
+		return 0, error
+	}
+	return uint64(lastInsertID), nil
+}
+
+//Fetch fetches the reports with the status, the oldest first
+func (repository Reports) Fetch(status string, limit, offset uint64) ([]models.Report, error) {
+	lines, error := repository.db.Query(`select `+reportColumns+` `+reportJoins+`
+	where r.status = ? order by r.createdAt, r.id limit ? offset ?`, status, limit, offset)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var reports []models.Report
+	for lines.Next() {
+		var report models.Report
+		if error = scanReport(lines, &report); error != nil {
+			return nil, error
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+//FetchByID fetches a report
+func (repository Reports) FetchByID(reportID uint64) (models.Report, error) {
+	lines, error := repository.db.Query(`select `+reportColumns+` `+reportJoins+` where r.id = ?`, reportID)
+	if error != nil {
+		return models.Report{}, error
+	}
+	defer lines.Close()
+
+	var report models.Report
+	if lines.Next() {
+		if error = scanReport(lines, &report); error != nil {
+			return models.Report{}, error
+		}
+	}
+	return report, nil
+}
+
+//Claim assigns an open report to the moderator. It returns false when the report is resolved or claimed by another moderator
+func (repository Reports) Claim(reportID, moderatorID uint64) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	if available, error := lockAvailableReport(transaction, reportID, moderatorID); error != nil || !available {
+		return false, error
+	}
+	if _, error = transaction.Exec(`update reports set status = 'claimed', moderator_id = ?, claimedAt = now() where id = ?`, moderatorID, reportID); error != nil {
+		return false, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//Resolve closes the report taking the action: hiding the reported post or suspending the reported user.
+//It returns false when the report is resolved or claimed by another moderator
+func (repository Reports) Resolve(report models.Report, moderatorID uint64, action string) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	if available, error := lockAvailableReport(transaction, report.ID, moderatorID); error != nil || !available {
+		return false, error
+	}
+	switch action {
+	case models.ActionHidePost:
+		_, error = transaction.Exec(`update posts set hidden = true where id = ?`, report.PostID)
+	case models.ActionSuspendAuthor:
+		_, error = transaction.Exec(`update users set suspended = true where id = ?`, report.UserID)
+	}
+	if error != nil {
+		return false, error
+	}
+	if _, error = transaction.Exec(`update reports set status = 'resolved', moderator_id = ?, action = ?, resolvedAt = now() where id = ?`,
+		moderatorID, action, report.ID,
+	); error != nil {
+		return false, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//lockAvailableReport locks the report and tells whether the moderator can work on it: it must be open or already claimed by them
+func lockAvailableReport(transaction *sql.Tx, reportID, moderatorID uint64) (bool, error) {
+	var available bool
+	error := transaction.QueryRow(`select status = 'open' or (status = 'claimed' and moderator_id = ?) from reports where id = ? for update`,
+		moderatorID, reportID,
+	).Scan(&available)
+	if error == sql.ErrNoRows {
+		return false, nil
+	}
+	return available, error
+}
+
+func scanReport(lines *sql.Rows, report *models.Report) error {
+	return lines.Scan(
+		&report.ID,
+		&report.ReporterID,
+		&report.ReporterNick,
+		&report.UserID,
+		&report.UserNick,
+		&report.PostID,
+		&report.PostContent,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.ModeratorID,
+		&report.Action,
+		&report.ClaimedAt,
+		&report.ResolvedAt,
+		&report.CreatedAt,
+	)
+}
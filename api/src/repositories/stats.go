@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+// Stats represents a repository of the daily stats of posts and authors
+type Stats struct {
+	db *sql.DB
+}
+
+//NewStatsRepository creates a stats repository
+func NewStatsRepository(db *sql.DB) *Stats {
+	return &Stats{db}
+}
+
+//RecordImpressions counts that the viewer was shown the posts today. A post is counted once per viewer
+//and day, and posts shown to their own author are not counted
+func (repository Stats) RecordImpressions(viewerID uint64, posts []models.Post) error {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return error
+	}
+	defer transaction.Rollback()
+
+	day := today()
+	for _, post := range posts {
+		if post.AuthorID == viewerID {
+			continue
+		}
+		result, error := transaction.Exec(`insert ignore into post_impressions (post_id, viewer_id, day) values (?, ?, ?)`, post.ID, viewerID, day)
+		if error != nil {
+			return error
+		}
+		rowsAffected, error := result.RowsAffected()
+		if error != nil {
+			return error
+		}
+		if rowsAffected == 0 {
+			continue
+		}
+		if _, error = transaction.Exec(`insert into post_daily_stats (post_id, day, impressions) values (?, ?, 1)
+		on duplicate key update impressions = impressions + 1`, post.ID, day); error != nil {
+			return error
+		}
+		if _, error = transaction.Exec(`insert into author_daily_stats (user_id, day, impressions) values (?, ?, 1)
+		on duplicate key update impressions = impressions + 1`, post.AuthorID, day); error != nil {
+			return error
+		}
+	}
+	return transaction.Commit()
+}
+
+//FetchAuthorStats fetches the daily stats of the author from the day on, the days without stats are left out
+func (repository Stats) FetchAuthorStats(userID uint64, since time.Time) ([]models.DailyStats, error) {
+	lines, error := repository.db.Query(`select day, impressions, likes, new_followers from author_daily_stats
+	where user_id = ? and day >= ? order by day`, userID, since.Format(models.DayLayout))
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanDailyStats(lines)
+}
+
+//FetchPostStats fetches the daily stats of the post from the day on, the days without stats are left out
+func (repository Stats) FetchPostStats(postID uint64, since time.Time) ([]models.DailyStats, error) {
+	lines, error := repository.db.Query(`select day, impressions, likes, 0 from post_daily_stats
+	where post_id = ? and day >= ? order by day`, postID, since.Format(models.DayLayout))
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanDailyStats(lines)
+}
+
+//countLike adds a like to the stats of the post and of its author for today
+func countLike(transaction *sql.Tx, postID uint64) error {
+	day := today()
+	if _, error := transaction.Exec(`insert into post_daily_stats (post_id, day, likes) values (?, ?, 1)
+	on duplicate key update likes = likes + 1`, postID, day); error != nil {
+		return error
+	}
+	if _, error := transaction.Exec(`insert into author_daily_stats (user_id, day, likes) select author_id, ?, 1 from posts where id = ?
+	on duplicate key update author_daily_stats.likes = author_daily_stats.likes + 1`, day, postID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//uncountLike takes a like back from the stats of the post and of its author for today, they never go below zero
+func uncountLike(transaction *sql.Tx, postID uint64) error {
+	day := today()
+	if _, error := transaction.Exec(`update post_daily_stats set likes = CASE WHEN likes > 0 THEN likes - 1 ELSE 0 END
+	where post_id = ? and day = ?`, postID, day); error != nil {
+		return error
+	}
+	if _, error := transaction.Exec(`update author_daily_stats a inner join posts p on p.author_id = a.user_id
+	set a.likes = CASE WHEN a.likes > 0 THEN a.likes - 1 ELSE 0 END where p.id = ? and a.day = ?`, postID, day); error != nil {
+		return error
+	}
+	return nil
+}
+
+//countNewFollower adds a new follower to the stats of the user for today
+func countNewFollower(transaction *sql.Tx, userID uint64) error {
+	_, error := transaction.Exec(`insert into author_daily_stats (user_id, day, new_followers) values (?, ?, 1)
+	on duplicate key update new_followers = new_followers + 1`, userID, today())
+	return error
+}
+
+//today is the day the stats are counted in
+func today() string {
+	return time.Now().Format(models.DayLayout)
+}
+
+func scanDailyStats(lines *sql.Rows) ([]models.DailyStats, error) {
+	var days []models.DailyStats
+	for lines.Next() {
+		var day time.Time
+		var dailyStats models.DailyStats
+		if error := lines.Scan(&day, &dailyStats.Impressions, &dailyStats.Likes, &dailyStats.NewFollowers); error != nil {
+			return nil, error
+		}
+		dailyStats.Day = day.Format(models.DayLayout)
+		days = append(days, dailyStats)
+	}
+	return days, nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+// maxCachedSuggestions is how many suggestions are kept for each user
+const maxCachedSuggestions = 100
+
+// Suggestions represents a follow suggestion repository
+type Suggestions struct {
+	db *sql.DB
+}
+
+//NewSuggestionRepository creates a follow suggestion repository
+func NewSuggestionRepository(db *sql.DB) *Suggestions {
+	return &Suggestions{db}
+}
+
+//Refresh computes again the suggestions of the user: the users followed by the users they follow,
+//scored by how many of them follow each one and by how much they posted in the last 30 days.
+//Users they already follow or asked to follow, blocked users and suspended users are left out
+func (repository Suggestions) Refresh(userID uint64) error {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return error
+	}
+	defer transaction.Rollback()
+
+	if _, error = transaction.Exec(`delete from follow_suggestions where user_id = ?`, userID); error != nil {
+		return error
+	}
+	if _, error = transaction.Exec(`insert into follow_suggestions (user_id, suggested_id, score, mutuals)
+	select ?, candidate.id, candidate.mutuals * (1 + ln(1 + (select count(*) from posts p
+		where p.author_id = candidate.id and p.status = 'published' and p.createdAt >= now() - interval 30 day))) score,
+		candidate.mutuals
+	from (
+		select u.id, count(*) mutuals from followers followed
+		inner join followers f on f.follower_id = followed.user_id
+		inner join users u on u.id = f.user_id
+		where followed.follower_id = ? and u.id <> ? and u.suspended = false
+		and not exists (select 1 from followers af where af.user_id = u.id and af.follower_id = ?)
+		and not exists (select 1 from follow_requests fr where fr.user_id = u.id and fr.requester_id = ?)
+		and `+notBlockedWith("u.id")+`
+		group by u.id
+	) candidate
+	order by score desc limit ?`,
+		userID, userID, userID, userID, userID, userID, userID, maxCachedSuggestions,
+	); error != nil {
+		return error
+	}
+	if _, error = transaction.Exec(`update users set suggestionsRefreshedAt = now() where id = ?`, userID); error != nil {
+		return error
+	}
+	return transaction.Commit()
+}
+
+//Postpone moves the user to the end of the refresh queue without changing their suggestions,
+//so a user whose refresh failed doesn't stay at the head of every batch
+func (repository Suggestions) Postpone(userID uint64) error {
+	statement, error := repository.db.Prepare(`update users set suggestionsRefreshedAt = now() where id = ?`)
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(userID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//Fetch fetches the cached suggestions of the user, the best first. Users followed or blocked
+//since the suggestions were computed are left out
+func (repository Suggestions) Fetch(userID, limit, offset uint64) ([]models.Suggestion, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+`, s.mutuals from follow_suggestions s
+	inner join users u on u.id = s.suggested_id
+	where s.user_id = ? and u.suspended = false
+	and not exists (select 1 from followers af where af.user_id = u.id and af.follower_id = ?)
+	and `+notBlockedWith("u.id")+`
+	order by s.score desc, u.id limit ? offset ?`,
+		userID, userID, userID, userID, userID, limit, offset,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var suggestions []models.Suggestion
+	for lines.Next() {
+		var suggestion models.Suggestion
+		if error = scanUser(lines, &suggestion.User, &suggestion.MutualFollows); error != nil {
+			return nil, error
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+//IsStale tells whether the suggestions of the user were never computed or were computed before the time
+func (repository Suggestions) IsStale(userID uint64, before time.Time) (bool, error) {
+	var stale bool
+	error := repository.db.QueryRow(`select suggestionsRefreshedAt is null or suggestionsRefreshedAt < ? from users where id = ?`,
+		before, userID,
+	).Scan(&stale)
+	if error != nil && error != sql.ErrNoRows {
+		return false, error
+	}
+	return stale, nil
+}
+
+//FetchStaleUserIDs fetches the users who asked for suggestions and whose suggestions were computed before the time, the oldest first
+func (repository Suggestions) FetchStaleUserIDs(before time.Time, limit uint64) ([]uint64, error) {
+	lines, error := repository.db.Query(`select id from users where suggestionsRefreshedAt < ? order by suggestionsRefreshedAt limit ?`, before, limit)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanIDs(lines)
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+// Tags represents a tag repository
+type Tags struct {
+	db *sql.DB
+}
+
+//NewTagRepository creates a tag repository
+func NewTagRepository(db *sql.DB) *Tags {
+	return &Tags{db}
+}
+
+//Trending fetches the tags used by more public posts since a moment, posts out of circulation are not counted
+func (repository Tags) Trending(since time.Time, limit uint64) ([]models.Tag, error) {
+	lines, error := repository.db.Query(`select pt.tag, count(*) from post_tags pt
+	inner join posts p on p.id = pt.post_id
+	inner join users u on u.id = p.author_id
+	where pt.createdAt >= ? and p.visibility = 'public' and u.private = false and `+inCirculation+`
+	group by pt.tag
+	order by count(*) desc, max(pt.createdAt) desc
+	limit ?`, since, limit)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var tags []models.Tag
+	for lines.Next() {
+		var tag models.Tag
+		if error = lines.Scan(&tag.Name, &tag.Posts); error != nil {
+			return nil, error
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+//Follow makes the posts with the tag appear in the timeline of the user
+func (repository Tags) Follow(tag string, userID uint64) error {
+	statement, error := repository.db.Prepare("insert ignore into tag_followers (tag, user_id) values(?, ?)")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(tag, userID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//Unfollow removes the posts with the tag from the timeline of the user
+func (repository Tags) Unfollow(tag string, userID uint64) error {
+	statement, error := repository.db.Prepare("delete from tag_followers where tag = ? and user_id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(tag, userID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//FetchFollowed fetches the tags the user follows
+func (repository Tags) FetchFollowed(userID uint64) ([]models.Tag, error) {
+	lines, error := repository.db.Query("select tag from tag_followers where user_id = ? order by tag", userID)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var tags []models.Tag
+	for lines.Next() {
+		var tag models.Tag
+		if error = lines.Scan(&tag.Name); error != nil {
+			return nil, error
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
@@ -4,6 +4,7 @@ import (
 	"api/src/models"
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // Users represents a user repository
@@ -18,12 +19,12 @@ func NewUserRespository(db *sql.DB) *Users {
 
 //Create inserts user in the database
 func (repository Users) Create(user models.User) (uint64, error) {
-	statement, error := repository.db.Prepare("insert into users (name, nick, email, password) values(?,?,?,?)")
+	statement, error := repository.db.Prepare("insert into users (name, nick, email, password, bio, location, website) values(?,?,?,?,?,?,?)")
 	if error != nil {
 		return 0, error
 	}
 	defer statement.Close()
-	result, error := statement.Exec(user.Name, user.Nick, user.Email, user.Password)
+	result, error := statement.Exec(user.Name, user.Nick, user.Email, user.Password, user.Bio, user.Location, user.Website)
 	if error != nil {
 		return 0, error
 	}
@@ -35,38 +36,35 @@ func (repository Users) Create(user models.User) (uint64, error) {
 	return uint64(lastInsertID), nil
 }
 
-//Fetch fetches all users based on a filter
-func (repository Users) Fetch(nameOrNick string) ([]models.User, error) {
-	nameOrNick = fmt.Sprintf("%%%s%%", nameOrNick)
-	lines, error := repository.db.Query("select id, name, nick, email, createdAt from users where name LIKE ? or nick LIKE ?", nameOrNick, nameOrNick)
-
+// publicUserColumns is the public projection of a user, the email is only
+// returned when the user is the viewer. It expects the viewer ID as its argument
+const publicUserColumns = "u.id, u.name, u.nick, if(u.id = ?, u.email, ''), u.bio, u.location, u.website, u.avatar_key, u.banner_key, u.createdAt"
+
+//Fetch searches users by the prefix of their nick or name, ranking exact nick
+//matches first, then the users followed by the viewer and then the most followed ones.
+//Users blocked by or blocking the viewer are left out
+func (repository Users) Fetch(nameOrNick string, viewerID, limit, offset uint64) ([]models.User, error) {
+	prefix := escapeLike(nameOrNick) + "%"
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u
+	where (u.nick like ? or u.name like ?) and `+notBlockedWith("u.id")+`
+	order by u.nick = ? desc,
+	exists (select 1 from followers f where f.user_id = u.id and f.follower_id = ?) desc,
+	(select count(*) from followers f where f.user_id = u.id) desc,
+	u.id
+	limit ? offset ?`,
+		viewerID, prefix, prefix, viewerID, viewerID, nameOrNick, viewerID, limit, offset,
+	)
 	if error != nil {
 		return nil, error
 	}
-
 	defer lines.Close()
 
-	var users []models.User
-
-	for lines.Next() {
-		var user models.User
-		if error = lines.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Nick,
-			&user.Email,
-			&user.CreatedAt,
-		); error != nil {
-			return nil, error
-		}
-		users = append(users, user)
-	}
-	return users, nil
+	return scanUsers(lines)
 }
 
-//FetchByID fetches a user from the database
-func (repository Users) FetchByID(ID uint64) (models.User, error) {
-	lines, error := repository.db.Query("select id, name, nick, email, createdAt from users where id = ?", ID)
+//FetchByID fetches a user from the database unless they blocked or were blocked by the viewer
+func (repository Users) FetchByID(ID, viewerID uint64) (models.User, error) {
+	lines, error := repository.db.Query("select "+publicUserColumns+" from users u where u.id = ? and "+notBlockedWith("u.id"), viewerID, ID, viewerID, viewerID)
 
 	if error != nil {
 		return models.User{}, error
@@ -74,26 +72,51 @@ func (repository Users) FetchByID(ID uint64) (models.User, error) {
 
 	defer lines.Close()
 
-	var user models.User
+	users, error := scanUsers(lines)
+	if error != nil || len(users) == 0 {
+		return models.User{}, error
+	}
+	return users[0], nil
+}
+
+//FetchProfile fetches a user with their post, follower and following counts, unless they blocked or were blocked by the viewer
+func (repository Users) FetchProfile(ID, viewerID uint64) (models.Profile, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+`,
+	(select count(*) from posts p where p.author_id = u.id and p.status = 'published'),
+	(select count(*) from followers f where f.user_id = u.id),
+	(select count(*) from followers f where f.follower_id = u.id)
+	from users u where u.id = ? and `+notBlockedWith("u.id"), viewerID, ID, viewerID, viewerID)
+	if error != nil {
+		return models.Profile{}, error
+	}
+	defer lines.Close()
+
+	var profile models.Profile
 	if lines.Next() {
 		if error = lines.Scan(
-
-			&user.ID,
-			&user.Name,
-			&user.Nick,
-			&user.Email,
-			&user.CreatedAt,
+			&profile.ID,
+			&profile.Name,
+			&profile.Nick,
+			&profile.Email,
+			&profile.Bio,
+			&profile.Location,
+			&profile.Website,
+			&profile.AvatarKey,
+			&profile.BannerKey,
+			&profile.CreatedAt,
+			&profile.PostCount,
+			&profile.FollowerCount,
+			&profile.FollowingCount,
 		); error != nil {
-			return models.User{}, error
+			return models.Profile{}, error
 		}
-
 	}
-	return user, nil
+	return profile, nil
 }
 
 // Update the information of the user
 func (repository Users) Update(ID uint64, user models.User) error {
-	statement, error := repository.db.Prepare("update users set name = ?, nick = ?, email = ? where id = ?")
+	statement, error := repository.db.Prepare("update users set name = ?, nick = ?, email = ?, bio = ?, location = ?, website = ? where id = ?")
 
 	if error != nil {
 		return error
@@ -101,7 +124,7 @@ func (repository Users) Update(ID uint64, user models.User) error {
 
 	defer statement.Close()
 
-	if _, error = statement.Exec(user.Name, user.Nick, user.Email, ID); error != nil {
+	if _, error = statement.Exec(user.Name, user.Nick, user.Email, user.Bio, user.Location, user.Website, ID); error != nil {
 		return error
 
 	}
@@ -121,6 +144,156 @@ func (repository Users) Delete(ID uint64) error {
 	return nil
 }
 
+//FetchImageKeys fetches the storage keys of the avatar and the banner of the user
+func (repository Users) FetchImageKeys(ID uint64) (string, string, error) {
+	var avatarKey, bannerKey string
+	error := repository.db.QueryRow("select avatar_key, banner_key from users where id = ?", ID).Scan(&avatarKey, &bannerKey)
+	if error != nil && error != sql.ErrNoRows {
+		return "", "", error
+	}
+	return avatarKey, bannerKey, nil
+}
+
+//UpdateAvatar replaces the avatar of the user, returning the key of the previous one so its files can be removed
+func (repository Users) UpdateAvatar(ID uint64, key string) (string, error) {
+	return repository.replaceImage("avatar_key", ID, key)
+}
+
+//UpdateBanner replaces the banner of the user, returning the key of the previous one so its file can be removed
+func (repository Users) UpdateBanner(ID uint64, key string) (string, error) {
+	return repository.replaceImage("banner_key", ID, key)
+}
+
+func (repository Users) replaceImage(column string, ID uint64, key string) (string, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return "", error
+	}
+	defer transaction.Rollback()
+
+	var previousKey string
+	if error = transaction.QueryRow("select "+column+" from users where id = ? for update", ID).Scan(&previousKey); error != nil {
+		return "", error
+	}
+	if _, error = transaction.Exec("update users set "+column+" = ? where id = ?", key, ID); error != nil {
+		return "", error
+	}
+	if error = transaction.Commit(); error != nil {
+		return "", error
+	}
+	return previousKey, nil
+}
+
+//FetchSettings fetches the privacy settings of the user
+func (repository Users) FetchSettings(ID uint64) (models.Settings, error) {
+	var settings models.Settings
+	if error := repository.db.QueryRow("select allow_dms, private from users where id = ?", ID).Scan(&settings.AllowDMs, &settings.Private); error != nil {
+		return models.Settings{}, error
+	}
+	return settings, nil
+}
+
+//UpdateSettings changes the privacy settings of the user
+func (repository Users) UpdateSettings(ID uint64, settings models.Settings) error {
+	statement, error := repository.db.Prepare("update users set allow_dms = ?, private = ? where id = ?")
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+	if _, error = statement.Exec(settings.AllowDMs, settings.Private, ID); error != nil {
+		return error
+	}
+	return nil
+}
+
+//IsPrivate tells whether only approved followers can see the posts of the user
+func (repository Users) IsPrivate(ID uint64) (bool, error) {
+	var private bool
+	if error := repository.db.QueryRow("select exists (select 1 from users where id = ? and private = true)", ID).Scan(&private); error != nil {
+		return false, error
+	}
+	return private, nil
+}
+
+//Exists tells whether there is a user with the ID
+func (repository Users) Exists(ID uint64) (bool, error) {
+	var exists bool
+	if error := repository.db.QueryRow("select exists (select 1 from users where id = ?)", ID).Scan(&exists); error != nil {
+		return false, error
+	}
+	return exists, nil
+}
+
+//IsModerator tells whether the user can work on the moderation queue
+func (repository Users) IsModerator(ID uint64) (bool, error) {
+	var moderator bool
+	if error := repository.db.QueryRow("select exists (select 1 from users where id = ? and moderator = true)", ID).Scan(&moderator); error != nil {
+		return false, error
+	}
+	return moderator, nil
+}
+
+//IsSuspended tells whether the user was suspended by a moderator
+func (repository Users) IsSuspended(ID uint64) (bool, error) {
+	var suspended bool
+	if error := repository.db.QueryRow("select exists (select 1 from users where id = ? and suspended = true)", ID).Scan(&suspended); error != nil {
+		return false, error
+	}
+	return suspended, nil
+}
+
+//IsFollowing tells whether the follower follows the user
+func (repository Users) IsFollowing(userID, followerID uint64) (bool, error) {
+	var following bool
+	if error := repository.db.QueryRow("select exists (select 1 from followers where user_id = ? and follower_id = ?)", userID, followerID).Scan(&following); error != nil {
+		return false, error
+	}
+	return following, nil
+}
+
+//FetchRelationships fetches how the viewer is connected to each of the users that exist, in no particular order
+func (repository Users) FetchRelationships(viewerID uint64, userIDs []uint64) ([]models.Relationship, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	arguments := []interface{}{viewerID, viewerID, viewerID, viewerID, viewerID, viewerID, viewerID}
+	for _, userID := range userIDs {
+		arguments = append(arguments, userID)
+	}
+	lines, error := repository.db.Query(`select u.id,
+	exists (select 1 from followers f where f.user_id = u.id and f.follower_id = ?),
+	exists (select 1 from followers f where f.user_id = ? and f.follower_id = u.id),
+	exists (select 1 from blocks b where b.user_id = ? and b.blocked_id = u.id),
+	exists (select 1 from blocks b where b.user_id = u.id and b.blocked_id = ?),
+	exists (select 1 from mutes m where m.user_id = ? and m.muted_id = u.id),
+	exists (select 1 from follow_requests fr where fr.user_id = u.id and fr.requester_id = ?),
+	exists (select 1 from follow_requests fr where fr.user_id = ? and fr.requester_id = u.id)
+	from users u where u.id in (`+placeholders(len(userIDs))+`)`, arguments...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	var relationships []models.Relationship
+	for lines.Next() {
+		var relationship models.Relationship
+		if error = lines.Scan(
+			&relationship.UserID,
+			&relationship.Following,
+			&relationship.FollowedBy,
+			&relationship.Blocked,
+			&relationship.BlockedBy,
+			&relationship.Muted,
+			&relationship.Requested,
+			&relationship.RequestedBy,
+		); error != nil {
+			return nil, error
+		}
+		relationships = append(relationships, relationship)
+	}
+	return relationships, nil
+}
+
 //FetchByEmail and returns the id and password with a hash
 func (repository Users) FetchByEmail(email string) (models.User, error) {
 	lines, error := repository.db.Query("select id, password from users where email = ?", email)
@@ -144,81 +317,188 @@ func (repository Users) FetchByEmail(email string) (models.User, error) {
 
 }
 
-//Follow allows an user to follow another
-func (repository Users) Follow(userID, followerID uint64) error {
-	statement, error := repository.db.Prepare("insert ignore into followers (user_id, follower_id) values(?,?)")
+//FetchIDsByNicks fetches the IDs of the users with the given nicks
+func (repository Users) FetchIDsByNicks(nicks []string) ([]uint64, error) {
+	if len(nicks) == 0 {
+		return nil, nil
+	}
+	arguments := make([]interface{}, len(nicks))
+	for i, nick := range nicks {
+		arguments[i] = nick
+	}
+	lines, error := repository.db.Query("select id from users where nick in ("+placeholders(len(nicks))+")", arguments...)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanIDs(lines)
+}
+
+//Follow allows an user to follow another. It returns false when the follower already followed the user
+func (repository Users) Follow(userID, followerID uint64) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec("insert ignore into followers (user_id, follower_id) values(?,?)", userID, followerID)
+	if error != nil {
+		return false, error
+	}
+	rowsAffected, error := result.RowsAffected()
+	if error != nil || rowsAffected == 0 {
+		return false, error
+	}
+	if error = countNewFollower(transaction, userID); error != nil {
+		return false, error
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//unfollow allows an user to unfollow another, cancelling the follow request if it is still pending
+func (repository Users) Unfollow(userID, followerID uint64) error {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return error
+	}
+	defer transaction.Rollback()
+
+	if _, error = transaction.Exec("delete from followers where user_id = ? and follower_id = ?", userID, followerID); error != nil {
+		return error
+	}
+	if _, error = transaction.Exec("delete from follow_requests where user_id = ? and requester_id = ?", userID, followerID); error != nil {
+		return error
+	}
+	return transaction.Commit()
+}
+
+//RequestFollow asks a private user to be followed
+func (repository Users) RequestFollow(userID, requesterID uint64) error {
+	statement, error := repository.db.Prepare("insert ignore into follow_requests (user_id, requester_id) values(?,?)")
 	if error != nil {
 		return error
 	}
 	defer statement.Close()
 
-	if _, error = statement.Exec(userID, followerID); error != nil {
+	if _, error = statement.Exec(userID, requesterID); error != nil {
 		return error
 	}
 	return nil
 }
 
-//unfollow allows an user to unfollow another
-func (repository Users) Unfollow(userID, followerID uint64) error {
-	statement, error := repository.db.Prepare("delete from followers where user_id = ? and follower_id = ?")
+//FetchFollowRequests fetches the users waiting for the approval of the user to follow them
+func (repository Users) FetchFollowRequests(userID uint64) ([]models.User, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u
+	inner join follow_requests r on u.id = r.requester_id
+	where r.user_id = ? order by r.createdAt`, userID, userID)
+	if error != nil {
+		return nil, error
+	}
+	defer lines.Close()
+
+	return scanUsers(lines)
+}
+
+//ApproveFollowRequest turns the pending request into a follow, it returns false when there was no request
+func (repository Users) ApproveFollowRequest(userID, requesterID uint64) (bool, error) {
+	transaction, error := repository.db.Begin()
+	if error != nil {
+		return false, error
+	}
+	defer transaction.Rollback()
+
+	result, error := transaction.Exec("delete from follow_requests where user_id = ? and requester_id = ?", userID, requesterID)
+	if error != nil {
+		return false, error
+	}
+	rowsAffected, error := result.RowsAffected()
+	if error != nil || rowsAffected == 0 {
+		return false, error
+	}
+	result, error = transaction.Exec("insert ignore into followers (user_id, follower_id) values(?,?)", userID, requesterID)
+	if error != nil {
+		return false, error
+	}
+	if rowsAffected, error = result.RowsAffected(); error != nil {
+		return false, error
+	}
+	if rowsAffected > 0 {
+		if error = countNewFollower(transaction, userID); error != nil {
+			return false, error
+		}
+	}
+	if error = transaction.Commit(); error != nil {
+		return false, error
+	}
+	return true, nil
+}
+
+//RejectFollowRequest removes the pending request
+func (repository Users) RejectFollowRequest(userID, requesterID uint64) error {
+	statement, error := repository.db.Prepare("delete from follow_requests where user_id = ? and requester_id = ?")
 	if error != nil {
 		return error
 	}
 	defer statement.Close()
-	if _, error = statement.Exec(userID, followerID); error != nil {
+	if _, error = statement.Exec(userID, requesterID); error != nil {
 		return error
 	}
 	return nil
 }
 
 //FetchFollowers from user
-func (repository Users) FetchFollowers(userID uint64) ([]models.User, error) {
-	lines, error := repository.db.Query(`select u.id, u.name, u.nick, u.email, u.createdAt from users u inner join followers s on u.id = s.follower_id where s.user_id = ?`, userID)
+func (repository Users) FetchFollowers(userID, viewerID uint64) ([]models.User, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u inner join followers s on u.id = s.follower_id
+	where s.user_id = ? and `+notBlockedWith("u.id"), viewerID, userID, viewerID, viewerID)
 	if error != nil {
 		return nil, error
 	}
 	defer lines.Close()
 
-	var users []models.User
-	for lines.Next() {
-		var user models.User
-		if error = lines.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Nick,
-			&user.Email,
-			&user.CreatedAt,
-		); error != nil {
-			return nil, error
-		}
-		users = append(users, user)
+	return scanUsers(lines)
+}
+
+//FetchFollowerIDs fetches the IDs of the followers of the user, leaving out those who muted them
+func (repository Users) FetchFollowerIDs(userID uint64) ([]uint64, error) {
+	lines, error := repository.db.Query(`select s.follower_id from followers s
+	where s.user_id = ? and not exists (select 1 from mutes m where m.user_id = s.follower_id and m.muted_id = s.user_id)`, userID)
+	if error != nil {
+		return nil, error
 	}
-	return users, nil
+	defer lines.Close()
+
+	return scanIDs(lines)
 }
 
 //FetchFollowing gets accounts the user follows
-func (repository Users) FetchFollowing(userID uint64) ([]models.User, error) {
-	lines, error := repository.db.Query(`select u.id, u.name, u.nick, u.email, u.createdAt from users u inner join followers s on u.id = s.user_id where s.follower_id = ?`, userID)
+func (repository Users) FetchFollowing(userID, viewerID uint64) ([]models.User, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u inner join followers s on u.id = s.user_id
+	where s.follower_id = ? and `+notBlockedWith("u.id"), viewerID, userID, viewerID, viewerID)
 	if error != nil {
 		return nil, error
 	}
 	defer lines.Close()
 
-	var users []models.User
-	for lines.Next() {
-		var user models.User
-		if error = lines.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Nick,
-			&user.Email,
-			&user.CreatedAt,
-		); error != nil {
-			return nil, error
-		}
-		users = append(users, user)
+	return scanUsers(lines)
+}
+
+//FetchMutuals fetches the followers of the user that the viewer follows
+func (repository Users) FetchMutuals(userID, viewerID, limit, offset uint64) ([]models.User, error) {
+	lines, error := repository.db.Query(`select `+publicUserColumns+` from users u inner join followers s on u.id = s.follower_id
+	where s.user_id = ? and exists (select 1 from followers vf where vf.user_id = u.id and vf.follower_id = ?)
+	and `+notBlockedWith("u.id")+`
+	order by u.nick limit ? offset ?`, viewerID, userID, viewerID, viewerID, viewerID, limit, offset)
+	if error != nil {
+		return nil, error
 	}
-	return users, nil
+	defer lines.Close()
+
+	return scanUsers(lines)
 }
 
 //FetchPassword from the user
@@ -252,3 +532,55 @@ func (repository Users) UpdatePassword(userID uint64, password string) error {
 	fmt.Printf(password)
 	return nil
 }
+
+//scanUser reads a line selected with the public user projection, followed by the extra columns
+func scanUser(lines *sql.Rows, user *models.User, extra ...interface{}) error {
+	return lines.Scan(append([]interface{}{
+		&user.ID,
+		&user.Name,
+		&user.Nick,
+		&user.Email,
+		&user.Bio,
+		&user.Location,
+		&user.Website,
+		&user.AvatarKey,
+		&user.BannerKey,
+		&user.CreatedAt,
+	}, extra...)...)
+}
+
+//scanUsers reads the lines selected with the public user projection
+func scanUsers(lines *sql.Rows) ([]models.User, error) {
+	var users []models.User
+	for lines.Next() {
+		var user models.User
+		if error := scanUser(lines, &user); error != nil {
+			return nil, error
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+//escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+//scanIDs reads lines with a single ID column
+func scanIDs(lines *sql.Rows) ([]uint64, error) {
+	var IDs []uint64
+	for lines.Next() {
+		var ID uint64
+		if error := lines.Scan(&ID); error != nil {
+			return nil, error
+		}
+		IDs = append(IDs, ID)
+	}
+	return IDs, nil
+}
+
+//placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var blocksRoute = []Route{
+	{
+		URI:                    "/blocks",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchBlockedUsers,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/block",
+		Method:                 http.MethodPost,
+		Function:               controllers.BlockUser,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/unblock",
+		Method:                 http.MethodPost,
+		Function:               controllers.UnblockUser,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/mutes",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchMutedUsers,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/mute",
+		Method:                 http.MethodPost,
+		Function:               controllers.MuteUser,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/unmute",
+		Method:                 http.MethodPost,
+		Function:               controllers.UnmuteUser,
+		RequiresAuthentication: true,
+	},
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var bookmarksRoute = []Route{
+	{
+		URI:                    "/posts/{postID}/bookmark",
+		Method:                 http.MethodPost,
+		Function:               controllers.BookmarkPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/bookmark",
+		Method:                 http.MethodDelete,
+		Function:               controllers.UnbookmarkPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/bookmarks",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchBookmarks,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/bookmarks/collections",
+		Method:                 http.MethodPost,
+		Function:               controllers.CreateCollection,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/bookmarks/collections",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchCollections,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/bookmarks/collections/{collectionID}",
+		Method:                 http.MethodPut,
+		Function:               controllers.UpdateCollection,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/bookmarks/collections/{collectionID}",
+		Method:                 http.MethodDelete,
+		Function:               controllers.DeleteCollection,
+		RequiresAuthentication: true,
+	},
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var draftsRoute = []Route{
+	{
+		URI:                    "/drafts",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchDrafts,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/scheduled",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchScheduledPosts,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/publish",
+		Method:                 http.MethodPost,
+		Function:               controllers.PublishPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/schedule",
+		Method:                 http.MethodPut,
+		Function:               controllers.SchedulePost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/schedule",
+		Method:                 http.MethodDelete,
+		Function:               controllers.UnschedulePost,
+		RequiresAuthentication: true,
+	},
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var followRequestsRoute = []Route{
+	{
+		URI:                    "/follow-requests",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchFollowRequests,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/follow-requests/{userID}/approve",
+		Method:                 http.MethodPost,
+		Function:               controllers.ApproveFollowRequest,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/follow-requests/{userID}/reject",
+		Method:                 http.MethodPost,
+		Function:               controllers.RejectFollowRequest,
+		RequiresAuthentication: true,
+	},
+}
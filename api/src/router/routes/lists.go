@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var listsRoute = []Route{
+	{
+		URI:                    "/lists",
+		Method:                 http.MethodPost,
+		Function:               controllers.CreateList,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/lists",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchUserLists,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/lists/{listID}",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchList,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/lists/{listID}",
+		Method:                 http.MethodPut,
+		Function:               controllers.UpdateList,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/lists/{listID}",
+		Method:                 http.MethodDelete,
+		Function:               controllers.DeleteList,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/lists/{listID}/members",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchListMembers,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/lists/{listID}/members/{userID}",
+		Method:                 http.MethodPost,
+		Function:               controllers.AddListMember,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/lists/{listID}/members/{userID}",
+		Method:                 http.MethodDelete,
+		Function:               controllers.RemoveListMember,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/lists/{listID}/posts",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchListPosts,
+		RequiresAuthentication: true,
+	},
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var mediaRoute = Route{
+	URI:                    "/media/{key:.+}",
+	Method:                 http.MethodGet,
+	Function:               controllers.ServeMedia,
+	RequiresAuthentication: false,
+}
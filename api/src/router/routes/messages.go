@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var messagesRoute = []Route{
+	{
+		URI:                    "/conversations",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchConversations,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/conversations/{conversationID}/messages",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchMessages,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/conversations/{conversationID}/messages",
+		Method:                 http.MethodPost,
+		Function:               controllers.SendMessage,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/conversations/{conversationID}/read",
+		Method:                 http.MethodPost,
+		Function:               controllers.MarkConversationRead,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/messages",
+		Method:                 http.MethodPost,
+		Function:               controllers.SendMessageToUser,
+		RequiresAuthentication: true,
+	},
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var notificationsRoute = []Route{
+	{
+		URI:                    "/notifications",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchNotifications,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/notifications/read",
+		Method:                 http.MethodPost,
+		Function:               controllers.MarkAllNotificationsRead,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/notifications/preferences",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchNotificationPreferences,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/notifications/preferences",
+		Method:                 http.MethodPut,
+		Function:               controllers.UpdateNotificationPreferences,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/notifications/{notificationID}/read",
+		Method:                 http.MethodPost,
+		Function:               controllers.MarkNotificationRead,
+		RequiresAuthentication: true,
+	},
+}
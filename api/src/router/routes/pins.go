@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var pinsRoute = []Route{
+	{
+		URI:                    "/posts/{postID}/pin",
+		Method:                 http.MethodPost,
+		Function:               controllers.PinPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/pin",
+		Method:                 http.MethodDelete,
+		Function:               controllers.UnpinPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/pins",
+		Method:                 http.MethodPut,
+		Function:               controllers.ReorderPinnedPosts,
+		RequiresAuthentication: true,
+	},
+}
@@ -18,12 +18,24 @@ var postsRoute = []Route{
 		Function:               controllers.FetchPosts,
 		RequiresAuthentication: true,
 	},
+	{
+		URI:                    "/posts/explore",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchExplore,
+		RequiresAuthentication: true,
+	},
 	{
 		URI:                    "/posts/{postID}",
 		Method:                 http.MethodGet,
 		Function:               controllers.FetchPost,
 		RequiresAuthentication: true,
 	},
+	{
+		URI:                    "/posts/{postID}/thread",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchThread,
+		RequiresAuthentication: true,
+	},
 	{
 		URI:                    "/posts/{postID}",
 		Method:                 http.MethodPut,
@@ -54,4 +66,34 @@ var postsRoute = []Route{
 		Function:               controllers.DislikePost,
 		RequiresAuthentication: true,
 	},
+	{
+		URI:                    "/posts/{postID}/repost",
+		Method:                 http.MethodPost,
+		Function:               controllers.RepostPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/unrepost",
+		Method:                 http.MethodPost,
+		Function:               controllers.UnrepostPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/attachments",
+		Method:                 http.MethodPost,
+		Function:               controllers.UploadAttachment,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/attachments/{attachmentID}",
+		Method:                 http.MethodDelete,
+		Function:               controllers.DeleteAttachment,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/posts/{postID}/stats",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchPostStats,
+		RequiresAuthentication: true,
+	},
 }
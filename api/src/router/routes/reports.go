@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var reportsRoute = []Route{
+	{
+		URI:                    "/posts/{postID}/report",
+		Method:                 http.MethodPost,
+		Function:               controllers.ReportPost,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/report",
+		Method:                 http.MethodPost,
+		Function:               controllers.ReportUser,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/moderation/reports",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchReports,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/moderation/reports/{reportID}/claim",
+		Method:                 http.MethodPost,
+		Function:               controllers.ClaimReport,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/moderation/reports/{reportID}/resolve",
+		Method:                 http.MethodPost,
+		Function:               controllers.ResolveReport,
+		RequiresAuthentication: true,
+	},
+}
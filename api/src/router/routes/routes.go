@@ -20,6 +20,18 @@ func Configure(r *mux.Router) *mux.Router {
 	routes := UserRoutes
 	routes = append(routes, loginRoute)
 	routes = append(routes, postsRoute...)
+	routes = append(routes, tagsRoute...)
+	routes = append(routes, notificationsRoute...)
+	routes = append(routes, streamRoute)
+	routes = append(routes, messagesRoute...)
+	routes = append(routes, blocksRoute...)
+	routes = append(routes, followRequestsRoute...)
+	routes = append(routes, mediaRoute)
+	routes = append(routes, draftsRoute...)
+	routes = append(routes, reportsRoute...)
+	routes = append(routes, bookmarksRoute...)
+	routes = append(routes, pinsRoute...)
+	routes = append(routes, listsRoute...)
 
 	for _, route := range routes {
 		if route.RequiresAuthentication {
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var streamRoute = Route{
+	URI:                    "/stream",
+	Method:                 http.MethodGet,
+	Function:               controllers.Stream,
+	RequiresAuthentication: true,
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var tagsRoute = []Route{
+	{
+		URI:                    "/tags/trending",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchTrendingTags,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/tags/following",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchFollowedTags,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/tags/{tag}/posts",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchTagPosts,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/tags/{tag}/follow",
+		Method:                 http.MethodPost,
+		Function:               controllers.FollowTag,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/tags/{tag}/unfollow",
+		Method:                 http.MethodPost,
+		Function:               controllers.UnfollowTag,
+		RequiresAuthentication: true,
+	},
+}
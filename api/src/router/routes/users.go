@@ -20,6 +20,20 @@ var UserRoutes = []Route{
 		RequiresAuthentication: true,
 	},
 
+	{
+		URI:                    "/users/suggestions",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchSuggestions,
+		RequiresAuthentication: true,
+	},
+
+	{
+		URI:                    "/users/relationships",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchRelationships,
+		RequiresAuthentication: true,
+	},
+
 	{
 		URI:                    "/users/{userID}",
 		Method:                 http.MethodGet,
@@ -72,4 +86,64 @@ var UserRoutes = []Route{
 		Function:               controllers.UpdatePassword,
 		RequiresAuthentication: true,
 	},
+	{
+		URI:                    "/users/{userID}/settings",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchSettings,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/settings",
+		Method:                 http.MethodPut,
+		Function:               controllers.UpdateSettings,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/avatar",
+		Method:                 http.MethodPost,
+		Function:               controllers.UploadAvatar,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/avatar",
+		Method:                 http.MethodDelete,
+		Function:               controllers.DeleteAvatar,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/banner",
+		Method:                 http.MethodPost,
+		Function:               controllers.UploadBanner,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/banner",
+		Method:                 http.MethodDelete,
+		Function:               controllers.DeleteBanner,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/relationship",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchRelationship,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/mutuals",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchMutuals,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/stats",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchUserStats,
+		RequiresAuthentication: true,
+	},
+	{
+		URI:                    "/users/{userID}/activity",
+		Method:                 http.MethodGet,
+		Function:               controllers.FetchUserActivity,
+		RequiresAuthentication: true,
+	},
 }
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+)
+
+// Job is a task the API runs periodically in the background
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func()
+}
+
+//Start runs every job right away, to catch up with what was missed while the API was stopped,
+//and then at every interval of the job. A run never overlaps the previous run of the same job
+func Start(jobs ...Job) {
+	for _, job := range jobs {
+		go loop(job)
+	}
+}
+
+func loop(job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		run(job)
+		<-ticker.C
+	}
+}
+
+//run keeps a job that panics from stopping the others or the API
+func run(job Job) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("job %s failed: %v", job.Name, recovered)
+		}
+	}()
+	job.Run()
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local keeps the files in a directory of the server
+type Local struct {
+	directory string
+	baseURL   string
+}
+
+//NewLocal creates a storage in the directory, its files are served from the base URL
+func NewLocal(directory, baseURL string) *Local {
+	return &Local{directory: directory, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+//Save writes the file, replacing it atomically when it already exists
+func (storage *Local) Save(key string, content []byte, contentType string) error {
+	path, error := storage.path(key)
+	if error != nil {
+		return error
+	}
+	if error = os.MkdirAll(filepath.Dir(path), 0755); error != nil {
+		return error
+	}
+	file, error := ioutil.TempFile(filepath.Dir(path), ".upload-*")
+	if error != nil {
+		return error
+	}
+	defer os.Remove(file.Name())
+	if _, error = file.Write(content); error != nil {
+		file.Close()
+		return error
+	}
+	if error = file.Close(); error != nil {
+		return error
+	}
+	if error = os.Chmod(file.Name(), 0644); error != nil {
+		return error
+	}
+	return os.Rename(file.Name(), path)
+}
+
+//Open reads the file
+func (storage *Local) Open(key string) (io.ReadCloser, error) {
+	path, error := storage.path(key)
+	if error != nil {
+		return nil, error
+	}
+	file, error := os.Open(path)
+	if errors.Is(error, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, error
+}
+
+//Delete removes the file, missing files are ignored
+func (storage *Local) Delete(key string) error {
+	path, error := storage.path(key)
+	if error != nil {
+		return error
+	}
+	if error = os.Remove(path); error != nil && !errors.Is(error, os.ErrNotExist) {
+		return error
+	}
+	return nil
+}
+
+//URL returns the public address of the file
+func (storage *Local) URL(key string) string {
+	return storage.baseURL + "/" + key
+}
+
+func (storage *Local) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("Invalid storage key %q", key)
+	}
+	return filepath.Join(storage.directory, filepath.FromSlash(key)), nil
+}
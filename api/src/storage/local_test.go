@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalSaveOpenDelete(t *testing.T) {
+	directory := t.TempDir()
+	storage := NewLocal(directory, "http://localhost:5000/media/")
+
+	content := []byte("hello, world")
+	if error := storage.Save("attachments/1/a.txt", content, "text/plain"); error != nil {
+		t.Fatalf("Save: %v", error)
+	}
+	stored, error := ioutil.ReadFile(filepath.Join(directory, "attachments", "1", "a.txt"))
+	if error != nil || !bytes.Equal(stored, content) {
+		t.Fatalf("stored %q, %v, want %q", stored, error, content)
+	}
+
+	replacement := []byte("replaced")
+	if error = storage.Save("attachments/1/a.txt", replacement, "text/plain"); error != nil {
+		t.Fatalf("Save over an existing file: %v", error)
+	}
+	file, error := storage.Open("attachments/1/a.txt")
+	if error != nil {
+		t.Fatalf("Open: %v", error)
+	}
+	read, error := ioutil.ReadAll(file)
+	file.Close()
+	if error != nil || !bytes.Equal(read, replacement) {
+		t.Fatalf("Open read %q, %v, want %q", read, error, replacement)
+	}
+
+	entries, error := os.ReadDir(filepath.Join(directory, "attachments", "1"))
+	if error != nil || len(entries) != 1 {
+		t.Fatalf("the directory holds %d files, %v, temporary files must be removed", len(entries), error)
+	}
+
+	if error = storage.Delete("attachments/1/a.txt"); error != nil {
+		t.Fatalf("Delete: %v", error)
+	}
+	if _, error = storage.Open("attachments/1/a.txt"); error != ErrNotFound {
+		t.Fatalf("Open after Delete returned %v, want ErrNotFound", error)
+	}
+	if error = storage.Delete("attachments/1/a.txt"); error != nil {
+		t.Fatalf("Delete of a missing file: %v", error)
+	}
+}
+
+func TestLocalURL(t *testing.T) {
+	storage := NewLocal(t.TempDir(), "http://localhost:5000/media/")
+	if url := storage.URL("attachments/1/a.png"); url != "http://localhost:5000/media/attachments/1/a.png" {
+		t.Errorf("URL is %s", url)
+	}
+}
+
+func TestLocalRefusesInvalidKeys(t *testing.T) {
+	parent := t.TempDir()
+	directory := filepath.Join(parent, "media")
+	storage := NewLocal(directory, "")
+
+	for _, key := range []string{"../outside.txt", "attachments/../../outside.txt", "/outside.txt"} {
+		if error := storage.Save(key, []byte("x"), "text/plain"); error == nil {
+			t.Errorf("Save accepted the key %q", key)
+		}
+		if _, error := storage.Open(key); error == nil {
+			t.Errorf("Open accepted the key %q", key)
+		}
+		if error := storage.Delete(key); error == nil {
+			t.Errorf("Delete accepted the key %q", key)
+		}
+	}
+	if _, error := os.Stat(filepath.Join(parent, "outside.txt")); !os.IsNotExist(error) {
+		t.Errorf("a file was written outside of the storage directory")
+	}
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 keeps the files in a bucket of an S3 compatible service, addressed in path style so it also works
+// with self hosted services like MinIO
+type S3 struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+}
+
+//NewS3 creates a storage in the bucket, its files are served from the public URL or from the endpoint when it is empty
+func NewS3(endpoint, region, bucket, accessKey, secretKey, publicURL string) *S3 {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if publicURL == "" {
+		publicURL = endpoint + "/" + bucket
+	}
+	return &S3{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+//Save uploads the file
+func (storage *S3) Save(key string, content []byte, contentType string) error {
+	response, error := storage.do(http.MethodPut, key, content, contentType)
+	if error != nil {
+		return error
+	}
+	defer response.Body.Close()
+	return checkResponse(response, http.StatusOK)
+}
+
+//Open downloads the file
+func (storage *S3) Open(key string) (io.ReadCloser, error) {
+	response, error := storage.do(http.MethodGet, key, nil, "")
+	if error != nil {
+		return nil, error
+	}
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, ErrNotFound
+	}
+	if error = checkResponse(response, http.StatusOK); error != nil {
+		response.Body.Close()
+		return nil, error
+	}
+	return response.Body, nil
+}
+
+//Delete removes the file
+func (storage *S3) Delete(key string) error {
+	response, error := storage.do(http.MethodDelete, key, nil, "")
+	if error != nil {
+		return error
+	}
+	defer response.Body.Close()
+	return checkResponse(response, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+//URL returns the public address of the file
+func (storage *S3) URL(key string) string {
+	return storage.publicURL + "/" + key
+}
+
+//do sends a request for the object signed with AWS Signature Version 4
+func (storage *S3) do(method, key string, content []byte, contentType string) (*http.Response, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("Invalid storage key %q", key)
+	}
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	request, error := http.NewRequest(method, storage.endpoint+"/"+url.PathEscape(storage.bucket)+"/"+strings.Join(segments, "/"), bytes.NewReader(content))
+	if error != nil {
+		return nil, error
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	storage.sign(request, content, time.Now().UTC())
+	return storage.client.Do(request)
+}
+
+//sign adds the AWS Signature Version 4 headers to the request
+func (storage *S3) sign(request *http.Request, content []byte, now time.Time) {
+	payloadHash := sha256Hex(content)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 request.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if contentType := request.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + storage.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+storage.secretKey), date)
+	signingKey = hmacSHA256(signingKey, storage.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		storage.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func checkResponse(response *http.Response, expectedStatusCodes ...int) error {
+	for _, statusCode := range expectedStatusCodes {
+		if response.StatusCode == statusCode {
+			return nil
+		}
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("Storage answered %s: %s", response.Status, strings.TrimSpace(string(body)))
+}
+
+func sha256Hex(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "us-east-1"
+	testBucket    = "media"
+)
+
+var authorizationHeader = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`)
+
+// fakeS3 is a stand-in for an S3 compatible service that checks the signature of each request
+// and keeps the objects in memory. Requests with a bad signature are answered with a 403 and the reason
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (fake *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, error := ioutil.ReadAll(r.Body)
+	if error != nil {
+		http.Error(w, error.Error(), http.StatusInternalServerError)
+		return
+	}
+	if error = verifySignature(r, body); error != nil {
+		http.Error(w, error.Error(), http.StatusForbidden)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/"+testBucket+"/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/"+testBucket+"/")
+
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		fake.objects[key] = body
+		fake.types[key] = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		content, found := fake.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+	case http.MethodDelete:
+		delete(fake.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+//verifySignature rebuilds the signature of the request from what was received, as the service would
+func verifySignature(r *http.Request, body []byte) error {
+	match := authorizationHeader.FindStringSubmatch(r.Header.Get("Authorization"))
+	if match == nil {
+		return fmt.Errorf("malformed Authorization header %q", r.Header.Get("Authorization"))
+	}
+	accessKey, date, region, signedHeaders, signature := match[1], match[2], match[3], match[4], match[5]
+	if accessKey != testAccessKey || region != testRegion {
+		return fmt.Errorf("credential for %s in %s, want %s in %s", accessKey, region, testAccessKey, testRegion)
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if !strings.HasPrefix(amzDate, date) {
+		return fmt.Errorf("X-Amz-Date %q doesn't match the credential date %s", amzDate, date)
+	}
+	payloadHash := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(payloadHash[:]) {
+		return errors.New("X-Amz-Content-Sha256 doesn't match the body")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	if !strings.Contains(";"+signedHeaders+";", ";host;") || !strings.Contains(signedHeaders, "x-amz-date") {
+		return fmt.Errorf("host and x-amz-date must be signed, signed headers are %s", signedHeaders)
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+testSecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	if expected := hex.EncodeToString(hmacSHA256(key, stringToSign)); signature != expected {
+		return fmt.Errorf("signature of %s %s is %s, want %s", r.Method, r.URL.Path, signature, expected)
+	}
+	return nil
+}
+
+func TestS3SaveOpenDelete(t *testing.T) {
+	fake, server := newFakeS3(t)
+	storage := NewS3(server.URL+"/", testRegion, testBucket, testAccessKey, testSecretKey, "")
+
+	content := []byte("hello, world")
+	if error := storage.Save("attachments/1/file name.txt", content, "text/plain"); error != nil {
+		t.Fatalf("Save: %v", error)
+	}
+	if stored := fake.objects["attachments/1/file name.txt"]; !bytes.Equal(stored, content) {
+		t.Fatalf("stored %q, want %q", stored, content)
+	}
+	if contentType := fake.types["attachments/1/file name.txt"]; contentType != "text/plain" {
+		t.Errorf("stored content type %q, want text/plain", contentType)
+	}
+
+	file, error := storage.Open("attachments/1/file name.txt")
+	if error != nil {
+		t.Fatalf("Open: %v", error)
+	}
+	read, error := ioutil.ReadAll(file)
+	file.Close()
+	if error != nil || !bytes.Equal(read, content) {
+		t.Fatalf("Open read %q, %v, want %q", read, error, content)
+	}
+
+	if error = storage.Delete("attachments/1/file name.txt"); error != nil {
+		t.Fatalf("Delete: %v", error)
+	}
+	if _, found := fake.objects["attachments/1/file name.txt"]; found {
+		t.Fatalf("the object is still stored after Delete")
+	}
+	if _, error = storage.Open("attachments/1/file name.txt"); error != ErrNotFound {
+		t.Fatalf("Open after Delete returned %v, want ErrNotFound", error)
+	}
+	if error = storage.Delete("attachments/1/file name.txt"); error != nil {
+		t.Fatalf("Delete of a missing object: %v", error)
+	}
+}
+
+func TestS3RejectsWrongSecret(t *testing.T) {
+	fake, server := newFakeS3(t)
+	storage := NewS3(server.URL, testRegion, testBucket, testAccessKey, "wrong", "")
+
+	error := storage.Save("key", []byte("x"), "text/plain")
+	if error == nil || !strings.Contains(error.Error(), "403") {
+		t.Fatalf("Save with a wrong secret returned %v, want a 403", error)
+	}
+	if len(fake.objects) != 0 {
+		t.Fatalf("the object was stored with a wrong signature")
+	}
+}
+
+func TestS3URL(t *testing.T) {
+	storage := NewS3("http://localhost:9000/", testRegion, testBucket, testAccessKey, testSecretKey, "")
+	if url := storage.URL("attachments/1/a.png"); url != "http://localhost:9000/media/attachments/1/a.png" {
+		t.Errorf("URL without a public URL is %s", url)
+	}
+	storage = NewS3("http://localhost:9000", testRegion, testBucket, testAccessKey, testSecretKey, "https://cdn.example.com/")
+	if url := storage.URL("attachments/1/a.png"); url != "https://cdn.example.com/attachments/1/a.png" {
+		t.Errorf("URL with a public URL is %s", url)
+	}
+}
+
+func TestS3RefusesInvalidKeys(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+	storage := NewS3(server.URL, testRegion, testBucket, testAccessKey, testSecretKey, "")
+
+	for _, key := range []string{"../secret", "attachments/../../secret", "/etc/passwd", ""} {
+		if error := storage.Save(key, []byte("x"), "text/plain"); error == nil {
+			t.Errorf("Save accepted the key %q", key)
+		}
+		if _, error := storage.Open(key); error == nil {
+			t.Errorf("Open accepted the key %q", key)
+		}
+		if error := storage.Delete(key); error == nil {
+			t.Errorf("Delete accepted the key %q", key)
+		}
+	}
+	if count := atomic.LoadInt32(&requests); count != 0 {
+		t.Errorf("%d requests reached the service with invalid keys", count)
+	}
+}
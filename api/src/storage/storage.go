@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"api/src/config"
+	"errors"
+	"io"
+	"log"
+	"strings"
+)
+
+// ErrNotFound is returned when there is no file with the key
+var ErrNotFound = errors.New("File not found")
+
+// Storage keeps the uploaded files
+type Storage interface {
+	Save(key string, content []byte, contentType string) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	URL(key string) string
+}
+
+// Default is the storage used by the API
+var Default Storage
+
+//Load creates the storage chosen in the configuration
+func Load() {
+	switch config.StorageDriver {
+	case "local":
+		Default = NewLocal(config.StoragePath, config.MediaURL)
+	case "s3":
+		if config.S3Endpoint == "" || config.S3Bucket == "" {
+			log.Fatal("S3_ENDPOINT and S3_BUCKET are required by the s3 storage")
+		}
+		Default = NewS3(config.S3Endpoint, config.S3Region, config.S3Bucket, config.S3AccessKey, config.S3SecretKey, config.S3PublicURL)
+	default:
+		log.Fatalf("Unknown storage driver %s", config.StorageDriver)
+	}
+}
+
+//validKey tells whether the key is a relative path without parent directories
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
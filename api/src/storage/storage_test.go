@@ -0,0 +1,28 @@
+package storage
+
+import "testing"
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"attachments/1/a.png", true},
+		{"avatars/2/file.name-400.jpg", true},
+		{"a", true},
+		{"", false},
+		{"../a", false},
+		{"attachments/../a", false},
+		{"attachments/..", false},
+		{"./a", false},
+		{"/etc/passwd", false},
+		{"attachments//a", false},
+		{"attachments/", false},
+		{`attachments\..\a`, false},
+	}
+	for _, test := range tests {
+		if valid := validKey(test.key); valid != test.valid {
+			t.Errorf("validKey(%q) = %v, want %v", test.key, valid, test.valid)
+		}
+	}
+}